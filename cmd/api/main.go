@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/DragonAirDragon/GO/internal/github"
 	"github.com/DragonAirDragon/GO/internal/handlers"
+	"github.com/DragonAirDragon/GO/internal/monitor"
+	"github.com/DragonAirDragon/GO/internal/notify"
+	"github.com/DragonAirDragon/GO/internal/storage"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+	"github.com/DragonAirDragon/GO/pkg/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	utils.LoadEnv()
+
 	router := gin.Default()
 
 	router.Use(cors.New(cors.Config{
@@ -22,8 +37,115 @@ func main() {
 
 	router.GET("/healthz", healthHandler.HealthCheck)
 
-	log.Println("Starting server on :8000")
-	if err := router.Run(":8000"); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	var (
+		telegramBot *telegram.Bot
+		manager     *monitor.Manager
+		store       storage.Store
+	)
+
+	webhookURL := os.Getenv("TELEGRAM_WEBHOOK_URL")
+	if webhookURL != "" {
+		telegramBot, manager, store = setupWebhook(router, webhookURL)
+	} else if os.Getenv("ADMIN_API_TOKEN") != "" {
+		// Недоставленными сообщениями управляет процесс, в котором работает бот.
+		log.Println("TELEGRAM_WEBHOOK_URL is not set: /admin/deadletters is served by cmd/bot on ADMIN_API_ADDR")
+	}
+
+	server := &http.Server{
+		Addr:    ":8000",
+		Handler: router,
+	}
+
+	go func() {
+		log.Println("Starting server on :8000")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to run server: %v", err)
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+
+	log.Println("Shutting down...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Failed to shut down server gracefully: %v", err)
+	}
+
+	if manager != nil {
+		manager.Shutdown()
+	}
+	if telegramBot != nil {
+		telegramBot.Stop()
+	}
+	if store != nil {
+		store.Close()
+	}
+}
+
+func setupWebhook(router *gin.Engine, webhookURL string) (*telegram.Bot, *monitor.Manager, storage.Store) {
+	telegramToken := os.Getenv("TELEGRAM_TOKEN")
+	if telegramToken == "" {
+		log.Fatalf("TELEGRAM_TOKEN is not set")
+	}
+
+	githubToken := os.Getenv("GITHUB_TOKEN")
+	if githubToken == "" {
+		log.Fatalf("GITHUB_TOKEN is not set")
+	}
+
+	webhookSecret := os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		log.Fatalf("TELEGRAM_WEBHOOK_SECRET is not set")
 	}
+
+	githubClient, err := github.NewClient(githubToken)
+	if err != nil {
+		log.Fatalf("Failed to create GitHub client: %v", err)
+	}
+
+	store, err := storage.Open(context.Background(), os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+
+	telegramBot, err := telegram.NewBot(telegramToken, store)
+	if err != nil {
+		log.Fatalf("Failed to create Telegram bot: %v", err)
+	}
+
+	mailer, err := notify.MailerFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure SMTP: %v", err)
+	}
+
+	admins, err := telegram.ParseAdminIDs(os.Getenv("TELEGRAM_ADMIN_IDS"))
+	if err != nil {
+		log.Fatalf("Invalid TELEGRAM_ADMIN_IDS: %v", err)
+	}
+	telegramBot.SetAdmins(admins)
+
+	manager := monitor.NewManager(githubClient, telegramBot, store, mailer)
+	go manager.Run()
+
+	if err := telegramBot.RestoreConfigs(context.Background()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	telegramHandler := handlers.NewTelegramHandler(telegramBot, webhookSecret)
+	router.POST("/telegram/webhook", telegramHandler.Webhook)
+
+	if adminToken := os.Getenv("ADMIN_API_TOKEN"); adminToken != "" {
+		handlers.NewDeadLetterHandler(manager, adminToken).Register(router)
+	}
+
+	if err := telegramBot.SetWebhook(webhookURL, webhookSecret); err != nil {
+		log.Fatalf("Failed to register Telegram webhook: %v", err)
+	}
+	log.Printf("Telegram webhook registered at %s", webhookURL)
+
+	return telegramBot, manager, store
 }
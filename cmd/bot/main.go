@@ -2,30 +2,27 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/DragonAirDragon/GO/internal/github"
-	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/handlers"
+	"github.com/DragonAirDragon/GO/internal/monitor"
+	"github.com/DragonAirDragon/GO/internal/notify"
+	"github.com/DragonAirDragon/GO/internal/storage"
 	"github.com/DragonAirDragon/GO/internal/telegram"
 	"github.com/DragonAirDragon/GO/pkg/utils"
+	"github.com/gin-gonic/gin"
 )
 
-type MonitoringState struct {
-	repos       map[string]bool
-	lastCommits map[string]string
-	ticker      *time.Ticker
-	cancel      context.CancelFunc
-}
-
 func main() {
 	utils.LoadEnv()
-	
+
 	telegramToken := os.Getenv("TELEGRAM_TOKEN")
 	if telegramToken == "" {
 		log.Fatalf("TELEGRAM_TOKEN is not set")
@@ -41,189 +38,72 @@ func main() {
 		log.Fatalf("Failed to create GitHub client: %v", err)
 	}
 
-	telegramBot, err := telegram.NewBot(telegramToken)
+	store, err := storage.Open(context.Background(), os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	defer store.Close()
+
+	telegramBot, err := telegram.NewBot(telegramToken, store)
 	if err != nil {
 		log.Fatalf("Failed to create Telegram bot: %v", err)
 	}
 
-	go telegramBot.StartCommandListener()
+	mailer, err := notify.MailerFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure SMTP: %v", err)
+	}
 
-	monitoringStates := make(map[int64]*MonitoringState)
-	statesMutex := sync.RWMutex{}
-
-	callbackChan := telegramBot.GetCallbackChannel()
-	go func() {
-		for callback := range callbackChan {
-			log.Printf("Received callback: %s for chat %d, username: %s", callback.Type, callback.ChatID, callback.Username)
-			
-			statesMutex.Lock()
-			
-			if callback.Type == "stop" {
-				if state, exists := monitoringStates[callback.ChatID]; exists && state.cancel != nil {
-					state.cancel()
-					if state.ticker != nil {
-						state.ticker.Stop()
-					}
-					delete(monitoringStates, callback.ChatID)
-					log.Printf("Monitoring stopped for chat %d", callback.ChatID)
-				}
-				statesMutex.Unlock()
-				continue
-			}
-			
-			if callback.Type == "update" {
-				if state, exists := monitoringStates[callback.ChatID]; exists {
-					if state.ticker != nil {
-						state.ticker.Stop()
-					}
-					state.ticker = time.NewTicker(time.Duration(callback.Interval) * time.Minute)
-					log.Printf("Updated interval to %d minutes for chat %d", callback.Interval, callback.ChatID)
-				}
-				statesMutex.Unlock()
-				continue
-			}
-			
-			if callback.Type == "start" {
-				if state, exists := monitoringStates[callback.ChatID]; exists && state.cancel != nil {
-					state.cancel()
-					if state.ticker != nil {
-						state.ticker.Stop()
-					}
-				}
-				
-				ctx, cancel := context.WithCancel(context.Background())
-				
-				state := &MonitoringState{
-					repos:       make(map[string]bool),
-					lastCommits: make(map[string]string),
-					ticker:      time.NewTicker(time.Duration(callback.Interval) * time.Minute),
-					cancel:      cancel,
-				}
-				
-				monitoringStates[callback.ChatID] = state
-				statesMutex.Unlock()
-				
-				go runMonitoring(ctx, callback.ChatID, callback.Username, callback.Interval, githubClient, telegramBot, state)
-				continue
-			}
-			
-			statesMutex.Unlock()
-		}
-	}()
+	admins, err := telegram.ParseAdminIDs(os.Getenv("TELEGRAM_ADMIN_IDS"))
+	if err != nil {
+		log.Fatalf("Invalid TELEGRAM_ADMIN_IDS: %v", err)
+	}
+	telegramBot.SetAdmins(admins)
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	manager := monitor.NewManager(githubClient, telegramBot, store, mailer)
+	go manager.Run()
 
-	log.Println("Shutting down...")
-	
-	statesMutex.Lock()
-	for chatID, state := range monitoringStates {
-		if state.cancel != nil {
-			state.cancel()
-		}
-		if state.ticker != nil {
-			state.ticker.Stop()
-		}
-		log.Printf("Stopped monitoring for chat %d", chatID)
+	if err := telegramBot.RestoreConfigs(context.Background()); err != nil {
+		log.Printf("Warning: %v", err)
 	}
-	statesMutex.Unlock()
-}
 
-func runMonitoring(ctx context.Context, chatID int64, username string, interval int, 
-	githubClient *github.Client, telegramBot *telegram.Bot, state *MonitoringState) {
-	
-	repos, err := githubClient.GetRepositories(ctx, username)
-	if err != nil {
-		log.Printf("Failed to get initial repositories for %s: %v", username, err)
-		telegramBot.SendMessage(chatID, "❌ Не удалось получить репозитории для пользователя <b>" + username + "</b>. Проверьте правильность имени пользователя.")
-		return
-	}
+	go telegramBot.StartCommandListener()
 
-	for _, repo := range repos {
-		state.repos[repo.Name] = true
-		
-		commits, err := githubClient.GetLatestCommit(ctx, username, repo.Name)
-		if err != nil {
-			log.Printf("Failed to get commits for %s: %v", repo.Name, err)
-			continue
-		}
-		if len(commits) > 0 {
-			state.lastCommits[repo.Name] = commits[0].SHA
+	// В режиме long polling HTTP сервера cmd/api нет, поэтому REST API недоставленных сообщений
+	// поднимается здесь.
+	var server *http.Server
+	if adminToken := os.Getenv("ADMIN_API_TOKEN"); adminToken != "" {
+		router := gin.Default()
+		handlers.NewDeadLetterHandler(manager, adminToken).Register(router)
+
+		addr := os.Getenv("ADMIN_API_ADDR")
+		if addr == "" {
+			addr = ":8001"
 		}
-	}
+		server = &http.Server{Addr: addr, Handler: router}
 
-	log.Printf("Started monitoring GitHub account: %s for chat %d", username, chatID)
-	log.Printf("Initial state: %d repositories", len(repos))
-	
-	telegramBot.SendMessage(chatID, fmt.Sprintf("✅ Мониторинг GitHub аккаунта <b>%s</b> запущен!\n"+
-		"Найдено репозиториев: %d\n"+
-		"Интервал проверки: %d минут", username, len(repos), interval))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-state.ticker.C:
-			currentRepos, err := githubClient.GetRepositories(ctx, username)
-			if err != nil {
-				log.Printf("Failed to get repositories for %s: %v", username, err)
-				continue
+		go func() {
+			log.Printf("Starting admin API on %s", addr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Failed to run admin API: %v", err)
 			}
+		}()
+	}
 
-			var newRepos []string
-			for _, repo := range currentRepos {
-				if _, exists := state.repos[repo.Name]; !exists {
-					newRepos = append(newRepos, repo.Name)
-					state.repos[repo.Name] = true
-				}
-			}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
 
-			if len(newRepos) > 0 {
-				message := "🆕 Обнаружены новые репозитории:\n"
-
-				for _, repoName := range newRepos {
-					var foundRepo *models.Repository
-					
-					for i := range currentRepos {
-						if currentRepos[i].Name == repoName {
-							foundRepo = &currentRepos[i]
-							break
-						}
-					}
-					
-					if foundRepo != nil {
-						message += "• " + foundRepo.Name + " - " + foundRepo.Description + "\n"
-						message += "  URL: " + foundRepo.URL + "\n\n"
-					}
-				}
-
-				telegramBot.SendMessage(chatID, message)
-			}
+	log.Println("Shutting down...")
 
-			for _, repo := range currentRepos {
-				commits, err := githubClient.GetLatestCommit(ctx, username, repo.Name)
-				if err != nil {
-					log.Printf("Failed to get commits for %s: %v", repo.Name, err)
-					continue
-				}
-
-				if len(commits) > 0 {
-					latestCommit := commits[0]
-					lastCommitSHA, exists := state.lastCommits[repo.Name]
-
-					if !exists || lastCommitSHA != latestCommit.SHA {
-						message := "📝 Новый коммит в репозитории " + repo.Name + ":\n"
-						message += "• Сообщение: " + latestCommit.Message + "\n"
-						message += "• Автор: " + latestCommit.Author + "\n"
-						message += "• Дата: " + latestCommit.Date + "\n"
-						message += "• URL: " + latestCommit.URL + "\n"
-
-						telegramBot.SendMessage(chatID, message)
-						state.lastCommits[repo.Name] = latestCommit.SHA
-					}
-				}
-			}
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Failed to shut down admin API gracefully: %v", err)
 		}
+		cancel()
 	}
+
+	manager.Shutdown()
+	telegramBot.Stop()
 }
@@ -1,6 +1,6 @@
 module github.com/DragonAirDragon/GO
 
-go 1.18
+go 1.23.0
 
 require (
 	github.com/gin-contrib/cors v1.7.5
@@ -0,0 +1,100 @@
+package changelog
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Типы Conventional Commits, которые не попадают в changelog.
+var hiddenTypes = map[string]bool{
+	"chore": true, "ci": true, "build": true, "docs": true, "style": true, "test": true,
+}
+
+type Changelog struct {
+	Breaking []models.Commit
+	Features []models.Commit
+	Fixes    []models.Commit
+	Other    []models.Commit // perf, refactor, revert и прочие типы
+	Skipped  int             // служебные и неоформленные коммиты
+}
+
+// Build раскладывает коммиты по разделам. Ломающие изменения попадают
+// только в Breaking, чтобы не дублироваться в остальных разделах.
+func Build(commits []models.Commit) Changelog {
+	var result Changelog
+
+	for _, commit := range commits {
+		cc := commit.Conventional
+		switch {
+		case cc.Breaking:
+			result.Breaking = append(result.Breaking, commit)
+		case cc.Type == "feat":
+			result.Features = append(result.Features, commit)
+		case cc.Type == "fix":
+			result.Fixes = append(result.Fixes, commit)
+		case cc.Type == "" || hiddenTypes[cc.Type]:
+			result.Skipped++
+		default:
+			result.Other = append(result.Other, commit)
+		}
+	}
+
+	return result
+}
+
+func (c Changelog) Empty() bool {
+	return len(c.Breaking)+len(c.Features)+len(c.Fixes)+len(c.Other) == 0
+}
+
+// Format собирает changelog в HTML для Telegram.
+func Format(title string, c Changelog) string {
+	var b strings.Builder
+	b.WriteString("📋 <b>" + html.EscapeString(title) + "</b>\n")
+
+	sections := []struct {
+		title   string
+		commits []models.Commit
+	}{
+		{"⚠️ Breaking changes", c.Breaking},
+		{"✨ Features", c.Features},
+		{"🐛 Fixes", c.Fixes},
+		{"🔧 Прочее", c.Other},
+	}
+	for _, section := range sections {
+		if len(section.commits) == 0 {
+			continue
+		}
+		b.WriteString("\n<b>" + section.title + "</b>\n")
+		for _, commit := range section.commits {
+			b.WriteString("• " + formatEntry(commit) + "\n")
+		}
+	}
+
+	if c.Empty() {
+		b.WriteString("\nКоммитов в формате Conventional Commits не найдено.\n")
+	}
+	if c.Skipped > 0 {
+		b.WriteString("\nПропущено служебных и неоформленных коммитов: " + strconv.Itoa(c.Skipped) + "\n")
+	}
+
+	return b.String()
+}
+
+func formatEntry(commit models.Commit) string {
+	cc := commit.Conventional
+
+	entry := ""
+	if cc.Scope != "" {
+		entry = "<b>" + html.EscapeString(cc.Scope) + ":</b> "
+	}
+	entry += html.EscapeString(cc.Description)
+
+	sha := commit.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return entry + ` (<a href="` + commit.URL + `">` + sha + `</a>)`
+}
@@ -0,0 +1,276 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	backgroundColor = color.RGBA{255, 255, 255, 255}
+	textColor       = color.RGBA{36, 41, 47, 255}
+	mutedColor      = color.RGBA{101, 109, 118, 255}
+	gridColor       = color.RGBA{216, 222, 228, 255}
+	barColor        = color.RGBA{64, 196, 99, 255}
+
+	// Уровни активности как в календаре вкладов GitHub.
+	heatmapLevels = []color.RGBA{
+		{235, 237, 240, 255},
+		{155, 233, 168, 255},
+		{64, 196, 99, 255},
+		{48, 161, 78, 255},
+		{33, 110, 57, 255},
+	}
+
+	palette = []color.RGBA{
+		{0, 173, 216, 255},
+		{241, 224, 90, 255},
+		{53, 114, 165, 255},
+		{222, 165, 132, 255},
+		{178, 7, 45, 255},
+		{112, 76, 178, 255},
+		{227, 76, 38, 255},
+		{86, 61, 124, 255},
+		{110, 118, 129, 255},
+	}
+)
+
+const (
+	textScale   = 2
+	titleHeight = 40
+	padding     = 20
+)
+
+// Item - подпись и значение для столбчатой и круговой диаграмм.
+type Item struct {
+	Label string
+	Value float64
+}
+
+// Bars рисует столбчатую диаграмму, например коммиты по дням.
+func Bars(title string, items []Item) ([]byte, error) {
+	const (
+		width      = 900
+		height     = 420
+		axisWidth  = 60
+		labelSpace = 30
+	)
+
+	img := newCanvas(width, height, title)
+
+	plotX, plotY := axisWidth, titleHeight+padding
+	plotW, plotH := width-axisWidth-padding, height-plotY-labelSpace-padding
+
+	maxValue := 0.0
+	for _, item := range items {
+		maxValue = math.Max(maxValue, item.Value)
+	}
+	top := niceCeil(maxValue)
+
+	const gridLines = 4
+	for i := 0; i <= gridLines; i++ {
+		y := plotY + plotH - plotH*i/gridLines
+		fillRect(img, plotX, y, plotW, 1, gridColor)
+		label := formatValue(top * float64(i) / gridLines)
+		drawText(img, plotX-8-textWidth(label, textScale), y-glyphHeight*textScale/2, label, mutedColor, textScale)
+	}
+
+	if len(items) == 0 {
+		return encode(img)
+	}
+
+	slot := plotW / len(items)
+	barWidth := max(slot*7/10, 1)
+
+	// Подписи не должны налезать друг на друга: при нехватке места выводится каждая n-я.
+	labelStep := 1
+	for _, item := range items {
+		for textWidth(item.Label, textScale)+8 > slot*labelStep {
+			labelStep++
+		}
+	}
+
+	for i, item := range items {
+		x := plotX + slot*i + (slot-barWidth)/2
+		barHeight := int(float64(plotH) * item.Value / top)
+		fillRect(img, x, plotY+plotH-barHeight, barWidth, barHeight, barColor)
+
+		if item.Value > 0 && barWidth >= textWidth(formatValue(item.Value), 1) {
+			value := formatValue(item.Value)
+			drawText(img, x+(barWidth-textWidth(value, 1))/2, plotY+plotH-barHeight-glyphHeight-4, value, textColor, 1)
+		}
+		if i%labelStep == 0 {
+			labelX := plotX + slot*i + (slot-textWidth(item.Label, textScale))/2
+			drawText(img, labelX, plotY+plotH+10, item.Label, mutedColor, textScale)
+		}
+	}
+
+	return encode(img)
+}
+
+// Heatmap рисует календарь активности за weeks недель, заканчивающийся днём end.
+// counts - число событий по датам в формате 2006-01-02; строки - дни недели с понедельника.
+func Heatmap(title string, counts map[string]int, end time.Time, weeks int) ([]byte, error) {
+	const (
+		cell      = 14
+		gap       = 3
+		axisWidth = 50
+		monthRow  = 24
+	)
+
+	width := axisWidth + weeks*(cell+gap) + padding
+	height := titleHeight + monthRow + 7*(cell+gap) + padding*2
+	img := newCanvas(width, height, title)
+
+	maxCount := 0
+	for _, count := range counts {
+		maxCount = max(maxCount, count)
+	}
+
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	weekdayOffset := (int(end.Weekday()) + 6) % 7 // понедельник - 0
+	start := end.AddDate(0, 0, -weekdayOffset-(weeks-1)*7)
+
+	gridY := titleHeight + monthRow
+	for i, label := range []string{"Mon", "", "Wed", "", "Fri", "", ""} {
+		if label != "" {
+			drawText(img, padding/2, gridY+i*(cell+gap)+(cell-glyphHeight)/2, label, mutedColor, 1)
+		}
+	}
+
+	lastMonth := time.Month(0)
+	labelEnd := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		index := int(day.Sub(start).Hours()/24 + 0.5)
+		column, row := index/7, index%7
+		x := axisWidth + column*(cell+gap)
+		y := gridY + row*(cell+gap)
+
+		if row == 0 && day.Month() != lastMonth {
+			lastMonth = day.Month()
+			if x >= labelEnd {
+				drawText(img, x, titleHeight+4, day.Format("Jan"), mutedColor, textScale)
+				labelEnd = x + textWidth("Jan", textScale) + gap*2
+			}
+		}
+
+		level := 0
+		if count := counts[day.Format("2006-01-02")]; count > 0 {
+			level = int(math.Ceil(float64(count) / float64(maxCount) * float64(len(heatmapLevels)-1)))
+		}
+		fillRect(img, x, y, cell, cell, heatmapLevels[level])
+	}
+
+	return encode(img)
+}
+
+// Breakdown рисует круговую диаграмму с легендой, например доли языков.
+// Мелкие доли сверх maxSlices объединяются в «Other».
+func Breakdown(title string, items []Item) ([]byte, error) {
+	const (
+		width     = 800
+		height    = 420
+		radius    = 150
+		maxSlices = 8
+	)
+
+	img := newCanvas(width, height, title)
+
+	items = append([]Item(nil), items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].Value > items[j].Value })
+	if len(items) > maxSlices {
+		other := Item{Label: "Other"}
+		for _, item := range items[maxSlices-1:] {
+			other.Value += item.Value
+		}
+		items = append(items[:maxSlices-1], other)
+	}
+
+	total := 0.0
+	for _, item := range items {
+		total += item.Value
+	}
+	if total == 0 {
+		drawText(img, padding, titleHeight+padding, "No data", mutedColor, textScale)
+		return encode(img)
+	}
+
+	cx, cy := padding+radius, titleHeight+(height-titleHeight)/2
+	for y := cy - radius; y <= cy+radius; y++ {
+		for x := cx - radius; x <= cx+radius; x++ {
+			dx, dy := float64(x-cx), float64(y-cy)
+			if dx*dx+dy*dy > radius*radius {
+				continue
+			}
+			// Угол отсчитывается от верхней точки по часовой стрелке.
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			img.Set(x, y, palette[sliceAt(items, total, angle/(2*math.Pi))%len(palette)])
+		}
+	}
+
+	legendX := cx + radius + padding*2
+	legendY := cy - len(items)*30/2
+	for i, item := range items {
+		y := legendY + i*30
+		fillRect(img, legendX, y, 16, 16, palette[i%len(palette)])
+		label := fmt.Sprintf("%s %.1f%%", item.Label, item.Value/total*100)
+		drawText(img, legendX+26, y+1, label, textColor, textScale)
+	}
+
+	return encode(img)
+}
+
+func sliceAt(items []Item, total, fraction float64) int {
+	cumulative := 0.0
+	for i, item := range items {
+		cumulative += item.Value / total
+		if fraction < cumulative {
+			return i
+		}
+	}
+	return len(items) - 1
+}
+
+func newCanvas(width, height int, title string) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, backgroundColor)
+	drawText(img, padding, padding/2+2, title, textColor, textScale)
+	return img
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// niceCeil округляет максимум шкалы вверх до 1, 2 или 5 с нужным порядком.
+func niceCeil(value float64) float64 {
+	if value <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if value <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func formatValue(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%d", int(value))
+	}
+	return fmt.Sprintf("%.1f", value)
+}
@@ -0,0 +1,231 @@
+package deps
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// parser извлекает пару «зависимость - версия» из строки манифеста.
+type parser func(line string) (name, version string, ok bool)
+
+// Манифест разбирается либо построчно по диффу (parse), либо целиком до и после коммита,
+// если зависимости лежат в JSON-объектах sections: по строке диффа не понять, в каком объекте она.
+type manifest struct {
+	ecosystem string
+	match     func(filename string) bool
+	parse     parser
+	sections  []string
+	skip      func(name string) bool // записи sections, которые не являются зависимостями
+}
+
+var manifests = []manifest{
+	{ecosystem: "Go", match: exact("go.mod"), parse: parseGoMod},
+	{ecosystem: "npm", match: exact("package.json"), sections: []string{"dependencies", "devDependencies", "peerDependencies"}},
+	// В require Composer лежат и требования к платформе (php, ext-*), у пакетов же всегда есть vendor.
+	{ecosystem: "Composer", match: exact("composer.json"), sections: []string{"require", "require-dev"}, skip: isPlatformPackage},
+	{ecosystem: "pip", match: isRequirements, parse: parseRequirements},
+	{ecosystem: "Cargo", match: exact("Cargo.toml"), parse: parseCargo},
+}
+
+// Diff разбирает диффы манифестов в коммите. Файлы без изменений зависимостей пропускаются.
+func Diff(files []models.CommitFile) []models.ManifestChange {
+	var result []models.ManifestChange
+
+	for _, file := range files {
+		for _, m := range manifests {
+			if !m.match(path.Base(file.Filename)) {
+				continue
+			}
+			if changes := m.diff(file); len(changes) > 0 {
+				result = append(result, models.ManifestChange{
+					File:      file.Filename,
+					Ecosystem: m.ecosystem,
+					Changes:   changes,
+				})
+			}
+			break
+		}
+	}
+
+	return result
+}
+
+// NeedsContent сообщает, что для разбора манифеста нужно содержимое файла до и после коммита,
+// а не только дифф (см. models.CommitFile.Content).
+func NeedsContent(filename string) bool {
+	for _, m := range manifests {
+		if m.match(path.Base(filename)) {
+			return m.sections != nil
+		}
+	}
+	return false
+}
+
+func (m manifest) diff(file models.CommitFile) []models.DependencyChange {
+	if m.sections == nil {
+		return compare(parsePatch(file.Patch, m.parse))
+	}
+
+	removed, err := parseJSON(file.PreviousContent, m.sections, m.skip)
+	if err != nil {
+		return nil
+	}
+	added, err := parseJSON(file.Content, m.sections, m.skip)
+	if err != nil {
+		return nil
+	}
+	return compare(removed, added)
+}
+
+// parsePatch возвращает зависимости из удалённых и добавленных строк диффа.
+func parsePatch(patch string, parse parser) (removed, added map[string]string) {
+	removed = make(map[string]string)
+	added = make(map[string]string)
+
+	for _, line := range strings.Split(patch, "\n") {
+		if line == "" || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		var target map[string]string
+		switch line[0] {
+		case '+':
+			target = added
+		case '-':
+			target = removed
+		default:
+			continue
+		}
+		if name, version, ok := parse(line[1:]); ok {
+			target[name] = version
+		}
+	}
+	return removed, added
+}
+
+// compare сравнивает зависимости до и после коммита; совпадающие пропускаются.
+func compare(removed, added map[string]string) []models.DependencyChange {
+	var changes []models.DependencyChange
+	for name, to := range added {
+		from, existed := removed[name]
+		if !existed {
+			changes = append(changes, models.DependencyChange{Name: name, Kind: models.DependencyAdded, To: to})
+			continue
+		}
+		if from != to {
+			changes = append(changes, models.DependencyChange{Name: name, Kind: models.DependencyUpdated, From: from, To: to})
+		}
+	}
+	for name, from := range removed {
+		if _, exists := added[name]; !exists {
+			changes = append(changes, models.DependencyChange{Name: name, Kind: models.DependencyRemoved, From: from})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func exact(name string) func(string) bool {
+	return func(filename string) bool { return filename == name }
+}
+
+func isRequirements(filename string) bool {
+	return strings.HasPrefix(filename, "requirements") && strings.HasSuffix(filename, ".txt")
+}
+
+var goModLine = regexp.MustCompile(`^\s*(?:require\s+)?([\w.\-~/]+\.[\w.\-~/]+)\s+(v[\w.\-+]+)`)
+
+func parseGoMod(line string) (string, string, bool) {
+	m := goModLine.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// parseJSON возвращает зависимости из объектов sections JSON-манифеста.
+// Пустое содержимое - файла нет, зависимостей тоже.
+func parseJSON(content string, sections []string, skip func(string) bool) (map[string]string, error) {
+	result := make(map[string]string)
+	if content == "" {
+		return result, nil
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &document); err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		raw, ok := document[section]
+		if !ok {
+			continue
+		}
+		// Записи, значение которых не строка, пропускаются.
+		var entries map[string]any
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, err
+		}
+		for name, value := range entries {
+			version, ok := value.(string)
+			if !ok || (skip != nil && skip(name)) {
+				continue
+			}
+			result[name] = version
+		}
+	}
+	return result, nil
+}
+
+func isPlatformPackage(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+var requirementLine = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._\-]*(?:\[[^\]]*\])?)\s*((?:==|>=|<=|~=|!=|===|>|<)\s*[^\s;#]+(?:\s*,\s*(?:==|>=|<=|~=|!=|>|<)\s*[^\s;#]+)*)?\s*(?:[;#].*)?$`)
+
+func parseRequirements(line string) (string, string, bool) {
+	m := requirementLine.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	name := strings.ToLower(m[1])
+	if i := strings.Index(name, "["); i != -1 {
+		name = name[:i]
+	}
+	// Точная версия показывается без "==", диапазоны - как есть.
+	version := strings.ReplaceAll(m[2], " ", "")
+	if strings.HasPrefix(version, "==") && !strings.ContainsAny(version[2:], "=<>!~,") {
+		version = version[2:]
+	}
+	return name, version, true
+}
+
+var (
+	cargoLine    = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+)\s*=\s*(?:"([^"]+)"|\{.*?version\s*=\s*"([^"]+)".*\})`)
+	cargoVersion = regexp.MustCompile(`^[\^~=<>*]?\s*\d`)
+)
+
+// Поля секции [package] Cargo.toml.
+var cargoReserved = map[string]bool{
+	"name": true, "version": true, "edition": true, "rust-version": true, "license": true,
+	"description": true, "readme": true, "resolver": true,
+}
+
+func parseCargo(line string) (string, string, bool) {
+	m := cargoLine.FindStringSubmatch(line)
+	if m == nil || cargoReserved[m[1]] {
+		return "", "", false
+	}
+	version := m[2]
+	if version == "" {
+		version = m[3]
+	}
+	if !cargoVersion.MatchString(version) {
+		return "", "", false
+	}
+	return m[1], version, true
+}
@@ -0,0 +1,104 @@
+package deps
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+func TestParseGoMod(t *testing.T) {
+	tests := []struct {
+		line    string
+		name    string
+		version string
+		ok      bool
+	}{
+		{"\tgithub.com/google/go-github/v60 v60.0.0", "github.com/google/go-github/v60", "v60.0.0", true},
+		{"require golang.org/x/oauth2 v0.18.0", "golang.org/x/oauth2", "v0.18.0", true},
+		{"\tgolang.org/x/sys v0.18.0 // indirect", "golang.org/x/sys", "v0.18.0", true},
+		{"module github.com/DragonAirDragon/GO", "", "", false},
+		{"go 1.22", "", "", false},
+		{"require (", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			name, version, ok := parseGoMod(tt.line)
+			if name != tt.name || version != tt.version || ok != tt.ok {
+				t.Errorf("parseGoMod() = %q, %q, %v; want %q, %q, %v", name, version, ok, tt.name, tt.version, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		line    string
+		name    string
+		version string
+		ok      bool
+	}{
+		{"Django==4.2.1", "django", "4.2.1", true},
+		{"requests >= 2.31, < 3", "requests", ">=2.31,<3", true},
+		{"uvicorn[standard]==0.29.0  # server", "uvicorn", "0.29.0", true},
+		{"black", "black", "", true},
+		{"# comment", "", "", false},
+		{"-r base.txt", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			name, version, ok := parseRequirements(tt.line)
+			if name != tt.name || version != tt.version || ok != tt.ok {
+				t.Errorf("parseRequirements() = %q, %q, %v; want %q, %q, %v", name, version, ok, tt.name, tt.version, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDiffPackageJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		content  string
+		want     []models.DependencyChange
+	}{
+		{
+			name:     "scripts are not dependencies",
+			previous: `{"scripts": {"build": "tsc"}, "dependencies": {"react": "^18.2.0"}}`,
+			content:  `{"scripts": {"build": "tsc -p .", "postinstall": "git submodule update"}, "dependencies": {"react": "^18.2.0"}}`,
+		},
+		{
+			name:     "all dependency sections",
+			previous: `{"dependencies": {"react": "^18.2.0", "lodash": "4.17.21"}, "devDependencies": {"typescript": "5.3.0"}}`,
+			content:  `{"dependencies": {"react": "^18.3.0"}, "devDependencies": {"typescript": "5.3.0"}, "peerDependencies": {"vue": "git+https://github.com/vuejs/core.git"}}`,
+			want: []models.DependencyChange{
+				{Name: "lodash", Kind: models.DependencyRemoved, From: "4.17.21"},
+				{Name: "react", Kind: models.DependencyUpdated, From: "^18.2.0", To: "^18.3.0"},
+				{Name: "vue", Kind: models.DependencyAdded, To: "git+https://github.com/vuejs/core.git"},
+			},
+		},
+		{
+			name:    "new file",
+			content: `{"name": "app", "version": "1.0.0", "dependencies": {"express": "^4.19.0"}}`,
+			want:    []models.DependencyChange{{Name: "express", Kind: models.DependencyAdded, To: "^4.19.0"}},
+		},
+		{
+			name:     "invalid JSON",
+			previous: `{"dependencies": {"react": "^18.2.0"}}`,
+			content:  `{"dependencies": {"react": "^18.3.0",}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := models.CommitFile{Filename: "web/package.json", Content: tt.content, PreviousContent: tt.previous}
+
+			var got []models.DependencyChange
+			if manifests := Diff([]models.CommitFile{file}); len(manifests) > 0 {
+				got = manifests[0].Changes
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Handler обрабатывает опубликованное событие. Ошибка означает, что событие нужно доставить повторно.
+type Handler func(ctx context.Context, event models.Event) error
+
+// Bus - шина событий внутри процесса. Обработчики вызываются синхронно в порядке подписки,
+// поэтому события одного аккаунта доставляются в порядке публикации.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[models.EventType][]Handler
+	all      []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[models.EventType][]Handler)}
+}
+
+// Subscribe подписывает обработчик на события одного типа.
+func (b *Bus) Subscribe(eventType models.EventType, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// SubscribeAll подписывает обработчик на все события.
+func (b *Bus) SubscribeAll(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.all = append(b.all, handler)
+}
+
+// Publish передаёт событие всем подписчикам и возвращает их ошибки.
+// Ошибка или паника одного обработчика не мешает остальным.
+func (b *Bus) Publish(ctx context.Context, event models.Event) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.all...), b.handlers[event.Kind()]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := call(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func call(ctx context.Context, handler Handler, event models.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			source := event.Source()
+			err = fmt.Errorf("handler panicked on %s in %s/%s: %v", event.Kind(), source.Account, source.Repo, r)
+		}
+	}()
+	return handler(ctx, event)
+}
@@ -0,0 +1,41 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+func ValidateAlert(rule models.AlertRule) error {
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if _, err := compile(alertExpr(rule)); err != nil {
+		return fmt.Errorf("invalid alert pattern: %w", err)
+	}
+	return nil
+}
+
+// MatchAlert возвращает первое правило, совпавшее с текстом (без учёта регистра).
+func MatchAlert(rules []models.AlertRule, text string) (models.AlertRule, bool) {
+	for _, rule := range rules {
+		re, err := compile(alertExpr(rule))
+		if err != nil {
+			continue
+		}
+		if re.MatchString(text) {
+			return rule, true
+		}
+	}
+	return models.AlertRule{}, false
+}
+
+// alertExpr экранирует ключевые слова, чтобы «c++» или «[security]» искались буквально.
+func alertExpr(rule models.AlertRule) string {
+	if rule.Keyword {
+		return "(?i)" + regexp.QuoteMeta(rule.Pattern)
+	}
+	return "(?i)" + rule.Pattern
+}
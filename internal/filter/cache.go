@@ -0,0 +1,38 @@
+package filter
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Правила проверяются для каждого события каждого чата, поэтому выражения компилируются
+// один раз - при сохранении правила в Validate или при первой проверке после перезапуска.
+// Кэш ограничен: удалённые правила не копятся в нём бесконечно.
+const maxCompiled = 1024
+
+var (
+	compiledMu sync.RWMutex
+	compiled   = make(map[string]*regexp.Regexp)
+)
+
+func compile(expr string) (*regexp.Regexp, error) {
+	compiledMu.RLock()
+	re, ok := compiled[expr]
+	compiledMu.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	compiledMu.Lock()
+	if len(compiled) >= maxCompiled {
+		compiled = make(map[string]*regexp.Regexp)
+	}
+	compiled[expr] = re
+	compiledMu.Unlock()
+	return re, nil
+}
@@ -0,0 +1,209 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Validate проверяет правило до сохранения, чтобы ошибки в шаблоне не всплывали при мониторинге.
+// Шаблон компилируется здесь же и дальше берётся из кэша.
+func Validate(rule models.FilterRule) error {
+	switch rule.Mode {
+	case models.FilterInclude, models.FilterExclude:
+	default:
+		return fmt.Errorf("unknown filter mode %q", rule.Mode)
+	}
+
+	switch rule.Field {
+	case models.FilterMessage, models.FilterAuthor, models.FilterBranch, models.FilterPath:
+	default:
+		return fmt.Errorf("unknown filter field %q", rule.Field)
+	}
+
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if _, err := compile(ruleExpr(rule.Field, rule.Pattern)); err != nil {
+		return fmt.Errorf("invalid %s pattern: %w", rule.Field, err)
+	}
+	return nil
+}
+
+// NeedsFiles сообщает, нужен ли для проверки список файлов коммита.
+func NeedsFiles(rules []models.FilterRule) bool {
+	for _, rule := range rules {
+		if rule.Field == models.FilterPath {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchPatterns возвращает шаблоны include-правил по веткам: кроме ветки по умолчанию,
+// опрашиваются только подходящие под них ветки.
+func BranchPatterns(rules []models.FilterRule) []string {
+	var patterns []string
+	for _, rule := range rules {
+		if rule.Field == models.FilterBranch && rule.Mode == models.FilterInclude {
+			patterns = append(patterns, rule.Pattern)
+		}
+	}
+	return patterns
+}
+
+// MatchBranch сравнивает имя ветки с glob-шаблоном в синтаксисе /filter branch.
+func MatchBranch(pattern, branch string) bool {
+	return globMatch(pattern, branch, false)
+}
+
+// Match применяет правила к коммиту. Для каждого поля: если есть include-правила,
+// коммит должен подойти хотя бы под одно; подходящее exclude-правило отбрасывает коммит.
+// Для путей exclude отбрасывает коммит, только если все изменённые файлы попали под исключение.
+// Коммит не из ветки по умолчанию проходит, только если его ветку явно включает правило.
+func Match(rules []models.FilterRule, commit models.Commit) bool {
+	byField := make(map[string][]models.FilterRule)
+	for _, rule := range rules {
+		byField[rule.Field] = append(byField[rule.Field], rule)
+	}
+
+	if commit.NonDefaultBranch {
+		included := false
+		for _, pattern := range BranchPatterns(byField[models.FilterBranch]) {
+			included = included || MatchBranch(pattern, commit.Branch)
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for field, fieldRules := range byField {
+		var ok bool
+		if field == models.FilterPath {
+			ok = matchPaths(fieldRules, commit.Files)
+		} else {
+			ok = matchValue(fieldRules, field, valueOf(field, commit))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func valueOf(field string, commit models.Commit) string {
+	switch field {
+	case models.FilterAuthor:
+		return commit.Author
+	case models.FilterMessage:
+		return commit.Message
+	case models.FilterBranch:
+		return commit.Branch
+	}
+	return ""
+}
+
+func matchValue(rules []models.FilterRule, field, value string) bool {
+	hasInclude, included := false, false
+	for _, rule := range rules {
+		matched := matchRule(rule, field, value)
+		if rule.Mode == models.FilterExclude && matched {
+			return false
+		}
+		if rule.Mode == models.FilterInclude {
+			hasInclude = true
+			included = included || matched
+		}
+	}
+	return !hasInclude || included
+}
+
+// matchPaths не пропускает коммит без списка файлов, если заданы include-правила:
+// без файлов нельзя проверить, что коммит затрагивает нужные пути.
+func matchPaths(rules []models.FilterRule, files []models.CommitFile) bool {
+	var includes, excludes []models.FilterRule
+	for _, rule := range rules {
+		if rule.Mode == models.FilterInclude {
+			includes = append(includes, rule)
+		} else {
+			excludes = append(excludes, rule)
+		}
+	}
+
+	if len(files) == 0 {
+		return len(includes) == 0
+	}
+
+	for _, file := range files {
+		if matchAny(excludes, file.Filename) {
+			continue
+		}
+		if len(includes) == 0 || matchAny(includes, file.Filename) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(rules []models.FilterRule, path string) bool {
+	for _, rule := range rules {
+		if matchRule(rule, models.FilterPath, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRule(rule models.FilterRule, field, value string) bool {
+	re, err := compile(ruleExpr(field, rule.Pattern))
+	return err == nil && re.MatchString(value)
+}
+
+// ruleExpr переводит шаблон правила в регулярное выражение: message задаётся им напрямую,
+// остальные поля - glob-шаблоном; автор сравнивается без учёта регистра.
+func ruleExpr(field, pattern string) string {
+	if field == models.FilterMessage {
+		return "(?i)" + pattern
+	}
+	return globExpr(pattern, field == models.FilterAuthor)
+}
+
+// MatchPath сравнивает путь файла с glob-шаблоном в синтаксисе /filter path.
+func MatchPath(pattern, path string) bool {
+	return globMatch(pattern, path, false)
+}
+
+func globMatch(pattern, value string, ignoreCase bool) bool {
+	re, err := compile(globExpr(pattern, ignoreCase))
+	return err == nil && re.MatchString(value)
+}
+
+// globExpr поддерживает * (любые символы кроме /), ** (любые символы) и ?.
+// Остальные символы сравниваются буквально, поэтому имена вроде dependabot[bot] работают как есть.
+func globExpr(pattern string, ignoreCase bool) string {
+	var b strings.Builder
+	b.WriteString("^")
+	if ignoreCase {
+		b.WriteString("(?i)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -2,6 +2,9 @@ package github
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/DragonAirDragon/GO/internal/models"
@@ -46,9 +49,9 @@ func (c *Client) GetRepositories(ctx context.Context, username string) ([]models
 				description = *repo.Description
 			}
 
-			createdAt := ""
+			var createdAt time.Time
 			if repo.CreatedAt != nil {
-				createdAt = repo.CreatedAt.Format(time.RFC3339)
+				createdAt = repo.CreatedAt.Time
 			}
 
 			repoURL := ""
@@ -57,10 +60,14 @@ func (c *Client) GetRepositories(ctx context.Context, username string) ([]models
 			}
 
 			allRepos = append(allRepos, models.Repository{
-				Name:        *repo.Name,
-				Description: description,
-				URL:         repoURL,
-				CreatedAt:   createdAt,
+				Name:          *repo.Name,
+				Description:   description,
+				URL:           repoURL,
+				DefaultBranch: repo.GetDefaultBranch(),
+				CreatedAt:     createdAt,
+				PushedAt:      repo.GetPushedAt().Time,
+				Language:      repo.GetLanguage(),
+				Fork:          repo.GetFork(),
 			})
 		}
 
@@ -73,8 +80,11 @@ func (c *Client) GetRepositories(ctx context.Context, username string) ([]models
 	return allRepos, nil
 }
 
-func (c *Client) GetLatestCommit(ctx context.Context, username, repo string) ([]models.Commit, error) {
+// GetLatestCommit возвращает последние коммиты ветки branch, от новых к старым;
+// пустой branch означает ветку по умолчанию.
+func (c *Client) GetLatestCommit(ctx context.Context, username, repo, branch string) ([]models.Commit, error) {
 	opt := &github.CommitsListOptions{
+		SHA:         branch,
 		ListOptions: github.ListOptions{PerPage: 5},
 	}
 
@@ -85,41 +95,352 @@ func (c *Client) GetLatestCommit(ctx context.Context, username, repo string) ([]
 
 	var result []models.Commit
 	for _, commit := range commits {
-		sha := ""
-		if commit.SHA != nil {
-			sha = *commit.SHA
+		result = append(result, convertCommit(commit))
+	}
+
+	return result, nil
+}
+
+// GetBranches возвращает ветки репозитория с SHA их последних коммитов.
+func (c *Client) GetBranches(ctx context.Context, username, repo string) (map[string]string, error) {
+	opt := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	result := make(map[string]string)
+	for {
+		branches, resp, err := c.client.Repositories.ListBranches(ctx, username, repo, opt)
+		if err != nil {
+			return nil, err
 		}
 
-		message := ""
-		if commit.Commit != nil && commit.Commit.Message != nil {
-			message = *commit.Commit.Message
+		for _, branch := range branches {
+			result[branch.GetName()] = branch.GetCommit().GetSHA()
 		}
 
-		author := ""
-		if commit.Author != nil && commit.Author.Login != nil {
-			author = *commit.Author.Login
-		} else if commit.Commit != nil && commit.Commit.Author != nil && commit.Commit.Author.Name != nil {
-			author = *commit.Commit.Author.Name
+		if resp.NextPage == 0 {
+			break
 		}
+		opt.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+// GetCommit загружает коммит вместе со списком изменённых файлов и их диффами.
+func (c *Client) GetCommit(ctx context.Context, username, repo, sha string) (*models.Commit, error) {
+	commit, _, err := c.client.Repositories.GetCommit(ctx, username, repo, sha, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := convertCommit(commit)
+	result.Additions = commit.GetStats().GetAdditions()
+	result.Deletions = commit.GetStats().GetDeletions()
+	if len(commit.Parents) > 0 {
+		result.Parent = commit.Parents[0].GetSHA()
+	}
+	for _, file := range commit.Files {
+		result.Files = append(result.Files, models.CommitFile{
+			Filename:  file.GetFilename(),
+			Status:    file.GetStatus(),
+			Additions: file.GetAdditions(),
+			Deletions: file.GetDeletions(),
+			Patch:     file.GetPatch(),
+		})
+	}
 
-		date := ""
-		if commit.Commit != nil && commit.Commit.Author != nil && commit.Commit.Author.Date != nil {
-			date = commit.Commit.Author.Date.Format(time.RFC3339)
+	return &result, nil
+}
+
+// GetFileContent возвращает содержимое файла на указанном коммите или пустую строку, если файла там нет.
+func (c *Client) GetFileContent(ctx context.Context, username, repo, path, ref string) (string, error) {
+	file, _, resp, err := c.client.Repositories.GetContents(ctx, username, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil
 		}
+		return "", err
+	}
+	if file == nil {
+		return "", fmt.Errorf("%s is not a file", path)
+	}
+	return file.GetContent()
+}
+
+// ListCommits возвращает не больше limit коммитов ветки по умолчанию за период, от новых к старым.
+func (c *Client) ListCommits(ctx context.Context, username, repo string, since, until time.Time, limit int) ([]models.Commit, error) {
+	opt := &github.CommitsListOptions{
+		Since:       since,
+		Until:       until,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
 
-		url := ""
-		if commit.HTMLURL != nil {
-			url = *commit.HTMLURL
+	var result []models.Commit
+	for {
+		commits, resp, err := c.client.Repositories.ListCommits(ctx, username, repo, opt)
+		if err != nil {
+			return nil, err
 		}
 
-		result = append(result, models.Commit{
-			SHA:     sha,
-			Message: message,
-			Author:  author,
-			Date:    date,
-			URL:     url,
+		for _, commit := range commits {
+			result = append(result, convertCommit(commit))
+			if len(result) >= limit {
+				return result, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+// GetReleases возвращает не больше limit последних релизов репозитория.
+func (c *Client) GetReleases(ctx context.Context, username, repo string, limit int) ([]models.Release, error) {
+	releases, _, err := c.client.Repositories.ListReleases(ctx, username, repo, &github.ListOptions{PerPage: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.Release
+	for _, release := range releases {
+		result = append(result, models.Release{
+			Name:        release.GetName(),
+			TagName:     release.GetTagName(),
+			URL:         release.GetHTMLURL(),
+			Draft:       release.GetDraft(),
+			Prerelease:  release.GetPrerelease(),
+			PublishedAt: release.GetPublishedAt().Time,
 		})
 	}
 
 	return result, nil
 }
+
+// GetPullRequests возвращает не больше limit pull request'ов репозитория в любом состоянии,
+// недавно изменённые первыми.
+func (c *Client) GetPullRequests(ctx context.Context, username, repo string, limit int) ([]models.PullRequest, error) {
+	opt := &github.PullRequestListOptions{
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: limit},
+	}
+
+	pulls, _, err := c.client.PullRequests.List(ctx, username, repo, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.PullRequest
+	for _, pull := range pulls {
+		result = append(result, models.PullRequest{
+			Number:    pull.GetNumber(),
+			Title:     pull.GetTitle(),
+			Author:    pull.GetUser().GetLogin(),
+			URL:       pull.GetHTMLURL(),
+			Base:      pull.GetBase().GetRef(),
+			Draft:     pull.GetDraft(),
+			CreatedAt: pull.GetCreatedAt().Time,
+			UpdatedAt: pull.GetUpdatedAt().Time,
+			ClosedAt:  pull.GetClosedAt().Time,
+			MergedAt:  pull.GetMergedAt().Time,
+		})
+	}
+
+	return result, nil
+}
+
+// GetCIStatus собирает итог проверок коммита: check runs и статусы внешних CI.
+// Упавшей считается проверка, завершившаяся ошибкой, отменой или по тайм-ауту;
+// пропущенные и нейтральные проверки на итог не влияют.
+func (c *Client) GetCIStatus(ctx context.Context, username, repo, sha string) (*models.CIStatus, error) {
+	runs, _, err := c.client.Checks.ListCheckRunsForRef(ctx, username, repo, sha, &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+	combined, _, err := c.client.Repositories.GetCombinedStatus(ctx, username, repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CIStatus{SHA: sha}
+	pending := false
+	for _, run := range runs.CheckRuns {
+		result.Total++
+		if run.GetStatus() != "completed" {
+			pending = true
+			continue
+		}
+		switch run.GetConclusion() {
+		case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+			result.Failed = append(result.Failed, run.GetName())
+		}
+	}
+	for _, status := range combined.Statuses {
+		result.Total++
+		switch status.GetState() {
+		case "pending":
+			pending = true
+		case "failure", "error":
+			result.Failed = append(result.Failed, status.GetContext())
+		}
+	}
+
+	switch {
+	case result.Total == 0:
+	case pending:
+		result.State = models.CIPending
+	case len(result.Failed) > 0:
+		result.State = models.CIFailure
+	default:
+		result.State = models.CISuccess
+	}
+	return result, nil
+}
+
+// CountMergedPullRequests считает PR, влитые за период в репозитории пользователя.
+func (c *Client) CountMergedPullRequests(ctx context.Context, username string, since, until time.Time) (int, error) {
+	query := fmt.Sprintf("user:%s is:pr is:merged merged:%s..%s", username,
+		since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+
+	result, _, err := c.client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return 0, err
+	}
+	return result.GetTotal(), nil
+}
+
+// GetLanguages возвращает объём кода репозитория по языкам в байтах.
+func (c *Client) GetLanguages(ctx context.Context, username, repo string) (map[string]int, error) {
+	languages, _, err := c.client.Repositories.ListLanguages(ctx, username, repo)
+	return languages, err
+}
+
+// CompareCommits возвращает коммиты между base и head (теги, ветки или SHA), от старых к новым.
+func (c *Client) CompareCommits(ctx context.Context, username, repo, base, head string) ([]models.Commit, error) {
+	opt := &github.ListOptions{PerPage: 100}
+
+	var result []models.Commit
+	for {
+		comparison, resp, err := c.client.Repositories.CompareCommits(ctx, username, repo, base, head, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commit := range comparison.Commits {
+			result = append(result, convertCommit(commit))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+// Сколько страниц тегов просматривает GetLatestTag.
+const maxTagPages = 10
+
+// GetLatestTag возвращает тег с наибольшей семантической версией или пустую строку, если тегов нет.
+// GitHub отдаёт теги не по версиям, поэтому просматриваются все (до maxTagPages страниц).
+// Если ни один тег не похож на версию, берётся тег последнего релиза, а без релизов - первый тег в списке.
+func (c *Client) GetLatestTag(ctx context.Context, username, repo string) (string, error) {
+	opt := &github.ListOptions{PerPage: 100}
+
+	var first, latest string
+	var latestVersion version
+	for page := 0; page < maxTagPages; page++ {
+		tags, resp, err := c.client.Repositories.ListTags(ctx, username, repo, opt)
+		if err != nil {
+			return "", err
+		}
+
+		for _, tag := range tags {
+			name := tag.GetName()
+			if first == "" {
+				first = name
+			}
+			if v, ok := parseVersion(name); ok && (latest == "" || latestVersion.less(v)) {
+				latest, latestVersion = name, v
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	if latest != "" || first == "" {
+		return latest, nil
+	}
+
+	release, resp, err := c.client.Repositories.GetLatestRelease(ctx, username, repo)
+	if err == nil && release.GetTagName() != "" {
+		return release.GetTagName(), nil
+	}
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return "", err
+	}
+	return first, nil
+}
+
+func (c *Client) GetDefaultBranch(ctx context.Context, username, repo string) (string, error) {
+	repository, _, err := c.client.Repositories.Get(ctx, username, repo)
+	if err != nil {
+		return "", err
+	}
+	return repository.GetDefaultBranch(), nil
+}
+
+// IsNotFound сообщает, что GitHub ответил 404: пользователя или репозитория нет.
+// Остальные ошибки (сеть, лимит запросов, сбой GitHub) считаются временными.
+func IsNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
+
+func convertCommit(commit *github.RepositoryCommit) models.Commit {
+	sha := ""
+	if commit.SHA != nil {
+		sha = *commit.SHA
+	}
+
+	message := ""
+	if commit.Commit != nil && commit.Commit.Message != nil {
+		message = *commit.Commit.Message
+	}
+
+	author := ""
+	if commit.Author != nil && commit.Author.Login != nil {
+		author = *commit.Author.Login
+	} else if commit.Commit != nil && commit.Commit.Author != nil && commit.Commit.Author.Name != nil {
+		author = *commit.Commit.Author.Name
+	}
+
+	var date time.Time
+	if commit.Commit != nil && commit.Commit.Author != nil && commit.Commit.Author.Date != nil {
+		date = commit.Commit.Author.Date.Time
+	}
+
+	url := ""
+	if commit.HTMLURL != nil {
+		url = *commit.HTMLURL
+	}
+
+	return models.Commit{
+		SHA:          sha,
+		Message:      message,
+		Author:       author,
+		Date:         date,
+		URL:          url,
+		Conventional: models.ParseConventional(message),
+	}
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version - разобранный тег вида v1.2.3, 1.2, go1.22.0 или v2.0.0-rc.1.
+type version struct {
+	numbers    [3]int
+	prerelease []string
+}
+
+// parseVersion разбирает тег как семантическую версию. Буквенный префикс (v, go, release-)
+// отбрасывается, недостающие минорная и патч-версии считаются нулями, метаданные сборки после +
+// не учитываются.
+func parseVersion(tag string) (version, bool) {
+	s := strings.TrimLeft(tag, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-_")
+	if i := strings.IndexByte(s, '+'); i != -1 {
+		s = s[:i]
+	}
+
+	var v version
+	core, pre, hasPre := strings.Cut(s, "-")
+	if hasPre {
+		if pre == "" {
+			return version{}, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return version{}, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
+
+// less сравнивает версии по правилам semver: пре-релиз младше релиза с теми же номерами.
+func (v version) less(other version) bool {
+	for i := range v.numbers {
+		if v.numbers[i] != other.numbers[i] {
+			return v.numbers[i] < other.numbers[i]
+		}
+	}
+
+	switch {
+	case len(v.prerelease) == 0:
+		return false
+	case len(other.prerelease) == 0:
+		return true
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		if a == b {
+			continue
+		}
+		na, errA := strconv.Atoi(a)
+		nb, errB := strconv.Atoi(b)
+		switch {
+		case errA == nil && errB == nil:
+			return na < nb
+		case errA == nil:
+			return true // числовые идентификаторы младше буквенных
+		case errB == nil:
+			return false
+		}
+		return a < b
+	}
+	return len(v.prerelease) < len(other.prerelease)
+}
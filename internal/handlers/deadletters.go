@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/monitor"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+// DeadLetterService - операции над недоставленными сообщениями, их реализует monitor.Manager.
+type DeadLetterService interface {
+	DeadLetters(ctx context.Context, limit int) ([]models.DeadLetter, error)
+	DeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id int64) error
+	DeleteDeadLetter(ctx context.Context, id int64) error
+}
+
+type DeadLetterHandler struct {
+	service DeadLetterService
+	token   string
+}
+
+func NewDeadLetterHandler(service DeadLetterService, token string) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		service: service,
+		token:   token,
+	}
+}
+
+// Register добавляет маршруты /admin/deadletters под проверкой токена.
+func (h *DeadLetterHandler) Register(router gin.IRouter) {
+	admin := router.Group("/admin", h.Authorize)
+	admin.GET("/deadletters", h.List)
+	admin.GET("/deadletters/:id", h.Get)
+	admin.POST("/deadletters/:id/replay", h.Replay)
+	admin.DELETE("/deadletters/:id", h.Delete)
+}
+
+// Authorize пропускает только запросы с заголовком Authorization: Bearer <ADMIN_API_TOKEN>.
+func (h *DeadLetterHandler) Authorize(c *gin.Context) {
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	c.Next()
+}
+
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	limit := defaultDeadLetterLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive integer",
+			})
+			return
+		}
+		limit = min(parsed, maxDeadLetterLimit)
+	}
+
+	letters, err := h.service.DeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if letters == nil {
+		letters = []models.DeadLetter{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": letters,
+	})
+}
+
+func (h *DeadLetterHandler) Get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	letter, err := h.service.DeadLetter(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, letter)
+}
+
+func (h *DeadLetterHandler) Replay(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReplayDeadLetter(c.Request.Context(), id); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status": "queued",
+	})
+}
+
+func (h *DeadLetterHandler) Delete(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDeadLetter(c.Request.Context(), id); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DeadLetterHandler) fail(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, monitor.ErrDeadLetterNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, monitor.ErrDeadLetterReplayed):
+		status = http.StatusConflict
+	}
+
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid dead letter ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/DragonAirDragon/GO/internal/telegram"
+	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+type TelegramHandler struct {
+	bot    *telegram.Bot
+	secret string
+}
+
+func NewTelegramHandler(bot *telegram.Bot, secret string) *TelegramHandler {
+	return &TelegramHandler{
+		bot:    bot,
+		secret: secret,
+	}
+}
+
+func (h *TelegramHandler) Webhook(c *gin.Context) {
+	token := c.GetHeader(telegramSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) != 1 {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid update payload",
+		})
+		return
+	}
+
+	h.bot.HandleUpdate(update)
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// AccountSnapshot - известное состояние репозиториев аккаунта. По нему опрос отличает
+// новые события от уже найденных; хранится вместе с outbox событий.
+type AccountSnapshot struct {
+	Repos     map[string]RepoSnapshot `json:"repos"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+type RepoSnapshot struct {
+	LastCommit  string            `json:"last_commit,omitempty"`
+	LastRelease time.Time         `json:"last_release"`       // последний известный релиз или время обнаружения репозитория
+	Branches    map[string]string `json:"branches,omitempty"` // ветка -> последний коммит веток из /filter include branch
+
+	// Время последнего известного изменения pull request; нулевое - PR ещё не проверялись.
+	LastPullRequest time.Time `json:"last_pull_request,omitempty"`
+	// Последний коммит ветки по умолчанию, о проверках CI которого уже известно; пусто - CI ещё не проверялся.
+	CICommit string `json:"ci_commit,omitempty"`
+}
+
+func NewAccountSnapshot() AccountSnapshot {
+	return AccountSnapshot{Repos: make(map[string]RepoSnapshot)}
+}
+
+func (s AccountSnapshot) Clone() AccountSnapshot {
+	cloned := AccountSnapshot{Repos: make(map[string]RepoSnapshot, len(s.Repos)), UpdatedAt: s.UpdatedAt}
+	for name, repo := range s.Repos {
+		if repo.Branches != nil {
+			branches := make(map[string]string, len(repo.Branches))
+			for branch, sha := range repo.Branches {
+				branches[branch] = sha
+			}
+			repo.Branches = branches
+		}
+		cloned.Repos[name] = repo
+	}
+	return cloned
+}
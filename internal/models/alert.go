@@ -0,0 +1,16 @@
+package models
+
+// AlertRule - ключевое слово или регулярное выражение, при совпадении с которым
+// событие отправляется как срочное.
+type AlertRule struct {
+	Pattern string
+	Keyword bool // Pattern ищется как обычный текст, без синтаксиса регулярных выражений
+}
+
+// DefaultAlertRules включаются командой /alert defaults; без неё срочных уведомлений нет.
+var DefaultAlertRules = []AlertRule{
+	{Pattern: "BREAKING", Keyword: true},
+	{Pattern: "security", Keyword: true},
+	{Pattern: "revert", Keyword: true},
+	{Pattern: `CVE-\d{4}-\d{4,}`},
+}
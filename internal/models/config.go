@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+	_ "time/tzdata" // база часовых поясов на случай, если в контейнере её нет
+)
+
+const (
+	DigestOff    = ""
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+
+	DigestGroupAccount = "account"
+	DigestGroupRepo    = "repo"
+
+	QuietHold   = "hold"
+	QuietSilent = "silent"
+)
+
+// MonitoringConfig - настройки подписки чата. Сохраняется в хранилище целиком в JSON.
+type MonitoringConfig struct {
+	GitHubUsername       string
+	CheckIntervalMinutes int
+	IsActive             bool
+	Paused               bool           // подписка сохранена, но уведомления не приходят до /resume
+	AllowMembers         bool           // в группах: могут ли не-администраторы управлять подписками
+	Routes               map[string]int // аккаунт или owner/repo -> message_thread_id темы форума
+	Digest               DigestSettings
+	TimeZone             string // IANA, например Europe/Moscow
+	Quiet                QuietSettings
+	SnoozeUntil          time.Time
+	DisabledEvents       map[EventType]bool // типы событий, выключенные в /settings
+	Filters              []FilterRule
+	AlertRules           []AlertRule // пусто - срочных уведомлений нет
+	EscalationChatID     int64       // чат, куда дублируются срочные уведомления
+	SecretScanOff        bool
+	SecretIgnores        []SecretIgnore
+	Report               ReportSettings
+	Sinks                []Sink           // Slack, Discord и webhook, куда уведомления дублируются
+	Emails               []EmailRecipient // адреса для сводок по почте
+}
+
+// ReportSettings - расписание еженедельного отчёта. По умолчанию отчёт приходит по понедельникам в 09:00.
+type ReportSettings struct {
+	Off      bool
+	Day      string // mon, tue, ..., sun
+	At       string // "HH:MM" по времени чата
+	LastSent time.Time
+}
+
+type DigestSettings struct {
+	Mode     string
+	At       string // время ежедневной сводки, "HH:MM"
+	GroupBy  string
+	Excluded map[EventType]bool // типы событий, которые всегда доставляются сразу
+}
+
+type QuietSettings struct {
+	Start string // "HH:MM" по времени чата, пустая строка - тихие часы выключены
+	End   string
+	Mode  string // QuietHold или QuietSilent
+}
+
+func (c *MonitoringConfig) Clone() MonitoringConfig {
+	cloned := *c
+
+	cloned.Routes = make(map[string]int, len(c.Routes))
+	for target, threadID := range c.Routes {
+		cloned.Routes[target] = threadID
+	}
+
+	cloned.Digest.Excluded = make(map[EventType]bool, len(c.Digest.Excluded))
+	for eventType, excluded := range c.Digest.Excluded {
+		cloned.Digest.Excluded[eventType] = excluded
+	}
+
+	cloned.DisabledEvents = make(map[EventType]bool, len(c.DisabledEvents))
+	for eventType, disabled := range c.DisabledEvents {
+		cloned.DisabledEvents[eventType] = disabled
+	}
+
+	cloned.Filters = append([]FilterRule(nil), c.Filters...)
+	cloned.SecretIgnores = append([]SecretIgnore(nil), c.SecretIgnores...)
+	cloned.Sinks = append([]Sink(nil), c.Sinks...)
+	cloned.Emails = append([]EmailRecipient(nil), c.Emails...)
+	cloned.AlertRules = append([]AlertRule(nil), c.AlertRules...)
+
+	return cloned
+}
+
+// Wants сообщает, включён ли тип события в настройках чата. По умолчанию включены все.
+func (c MonitoringConfig) Wants(eventType EventType) bool {
+	return !c.DisabledEvents[eventType]
+}
+
+// Location возвращает часовой пояс чата, по умолчанию UTC.
+func (c MonitoringConfig) Location() *time.Location {
+	if c.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietMode сообщает, как доставлять уведомления сейчас: "" - обычно,
+// QuietHold - придержать, QuietSilent - отправить без звука. Snooze всегда придерживает.
+func (c MonitoringConfig) QuietMode(now time.Time) string {
+	if now.Before(c.SnoozeUntil) {
+		return QuietHold
+	}
+
+	if c.Quiet.Start == "" || !c.inQuietHours(now) {
+		return ""
+	}
+
+	if c.Quiet.Mode == QuietSilent {
+		return QuietSilent
+	}
+	return QuietHold
+}
+
+func (c MonitoringConfig) inQuietHours(now time.Time) bool {
+	start, errStart := time.Parse("15:04", c.Quiet.Start)
+	end, errEnd := time.Parse("15:04", c.Quiet.End)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	local := now.In(c.Location())
+	minutes := local.Hour()*60 + local.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	if startMinutes <= endMinutes {
+		return minutes >= startMinutes && minutes < endMinutes
+	}
+	// Интервал через полночь, например 23:00-08:00.
+	return minutes >= startMinutes || minutes < endMinutes
+}
+
+// FormatTime показывает время в часовом поясе чата.
+func (c MonitoringConfig) FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.In(c.Location()).Format("02.01.2006 15:04 MST")
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit - разобранный заголовок коммита в формате Conventional Commits:
+// type(scope)!: description. Для обычных коммитов Type пустой.
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+var conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+
+// ParseConventional разбирает сообщение коммита. Breaking выставляется и по «!»
+// в заголовке, и по футеру BREAKING CHANGE.
+func ParseConventional(message string) ConventionalCommit {
+	lines := strings.Split(message, "\n")
+	m := conventionalHeader.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return ConventionalCommit{}
+	}
+
+	result := ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[2],
+		Breaking:    m[3] == "!",
+		Description: m[4],
+	}
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			result.Breaking = true
+		}
+	}
+	return result
+}
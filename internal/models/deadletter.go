@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Каналы, в которые не удалось доставить сообщение.
+const (
+	DeadLetterTelegram = "telegram" // сообщение в чат; переотправляется как есть
+	DeadLetterSink     = "sink"     // уведомление или сводка внешнему получателю из /sink
+	DeadLetterEmail    = "email"    // почтовая сводка
+	DeadLetterEvent    = "event"    // событие outbox, которое не удалось разослать подписчикам шины
+)
+
+// DeadLetter - сообщение, которое не удалось доставить после всех попыток.
+// Хранится, пока администратор не переотправит или не удалит его.
+type DeadLetter struct {
+	ID         int64      `json:"id"`
+	Channel    string     `json:"channel"`
+	ChatID     int64      `json:"chat_id"`
+	ThreadID   int        `json:"thread_id,omitempty"`
+	Target     string     `json:"target,omitempty"` // адрес получателя или ID события; для Telegram пусто
+	Text       string     `json:"text"`
+	Silent     bool       `json:"silent,omitempty"`
+	Photo      []byte     `json:"photo,omitempty"` // PNG графика; в списках не загружается
+	Payload    []byte     `json:"-"`               // что переотправлять, для каналов кроме Telegram; формат знает канал
+	Error      string     `json:"error"`
+	Attempts   int        `json:"attempts"`
+	FailedAt   time.Time  `json:"failed_at"`
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// Виды изменения зависимости.
+const (
+	DependencyAdded   = "added"
+	DependencyRemoved = "removed"
+	DependencyUpdated = "updated"
+)
+
+// DependencyChange - изменение одной зависимости. Версия бывает пустой и у добавленной,
+// и у удалённой зависимости (например, строка requirements.txt без версии), поэтому вид
+// изменения хранится явно.
+type DependencyChange struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ManifestChange - изменения зависимостей в одном файле манифеста.
+type ManifestChange struct {
+	File      string             `json:"file"`
+	Ecosystem string             `json:"ecosystem"`
+	Changes   []DependencyChange `json:"changes"`
+}
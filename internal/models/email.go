@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// EmailRecipient - адрес, на который приходят сводки событий подписки по своему расписанию.
+// Сводки уходят только на подтверждённый адрес: владелец адреса присылает боту код из письма,
+// иначе /email позволял бы рассылать письма на чужие адреса.
+type EmailRecipient struct {
+	Address string
+	Mode    string // DigestHourly или DigestDaily
+	At      string // "HH:MM" по времени чата, для ежедневной сводки
+
+	Confirmed    bool
+	Code         string    // код подтверждения; пусто, если код не выдан или истёк
+	CodeSent     time.Time // когда отправлено письмо с кодом
+	CodeAttempts int       // неверные попытки ввести код
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventNewRepo     EventType = "new_repo"
+	EventCommit      EventType = "commit"
+	EventRelease     EventType = "release"
+	EventPullRequest EventType = "pull_request"
+	EventCI          EventType = "ci"
+	EventDependency  EventType = "dependency"
+)
+
+// EventTypes перечисляет все типы событий в порядке показа пользователю.
+var EventTypes = []EventType{EventNewRepo, EventCommit, EventRelease, EventPullRequest, EventCI, EventDependency}
+
+// Event - событие, обнаруженное при опросе GitHub. Детекторы публикуют события в шину,
+// а доставка, фильтры и хранилище получают их независимо друг от друга.
+type Event interface {
+	Kind() EventType
+	Source() EventSource
+}
+
+// EventSource - общие поля всех событий.
+type EventSource struct {
+	Account    string    `json:"account"`
+	Repo       string    `json:"repo"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+func (s EventSource) Source() EventSource { return s }
+
+// RepoCreated - в аккаунте появился новый репозиторий.
+type RepoCreated struct {
+	EventSource
+	Repository Repository `json:"repository"`
+}
+
+func (RepoCreated) Kind() EventType { return EventNewRepo }
+
+// CommitPushed - в ветку по умолчанию или ветку из /filter include branch запушен новый коммит.
+type CommitPushed struct {
+	EventSource
+	Commit   Commit `json:"commit"`
+	Previous string `json:"previous,omitempty"` // прошлый известный коммит ветки; пусто, если неизвестен
+}
+
+func (CommitPushed) Kind() EventType { return EventCommit }
+
+// ReleasePublished - опубликован релиз (черновики не учитываются).
+type ReleasePublished struct {
+	EventSource
+	Release Release `json:"release"`
+}
+
+func (ReleasePublished) Kind() EventType { return EventRelease }
+
+// PullRequestChanged - pull request открыт, влит или закрыт без слияния.
+type PullRequestChanged struct {
+	EventSource
+	Action      string      `json:"action"` // PullRequestOpened, PullRequestMerged или PullRequestClosed
+	PullRequest PullRequest `json:"pull_request"`
+}
+
+func (PullRequestChanged) Kind() EventType { return EventPullRequest }
+
+// CICompleted - завершились проверки CI последнего коммита ветки по умолчанию.
+type CICompleted struct {
+	EventSource
+	Status CIStatus `json:"status"`
+}
+
+func (CICompleted) Kind() EventType { return EventCI }
+
+// DecodeEvent восстанавливает событие из JSON по его типу.
+func DecodeEvent(kind EventType, data []byte) (Event, error) {
+	switch kind {
+	case EventNewRepo:
+		var e RepoCreated
+		err := json.Unmarshal(data, &e)
+		return e, err
+	case EventCommit:
+		var e CommitPushed
+		err := json.Unmarshal(data, &e)
+		e.Commit.Conventional = ParseConventional(e.Commit.Message)
+		return e, err
+	case EventRelease:
+		var e ReleasePublished
+		err := json.Unmarshal(data, &e)
+		return e, err
+	case EventPullRequest:
+		var e PullRequestChanged
+		err := json.Unmarshal(data, &e)
+		return e, err
+	case EventCI:
+		var e CICompleted
+		err := json.Unmarshal(data, &e)
+		return e, err
+	}
+	return nil, fmt.Errorf("unknown event kind %q", kind)
+}
@@ -0,0 +1,19 @@
+package models
+
+const (
+	FilterInclude = "include"
+	FilterExclude = "exclude"
+
+	FilterAuthor  = "author"
+	FilterMessage = "message"
+	FilterBranch  = "branch"
+	FilterPath    = "path"
+)
+
+// FilterRule - правило фильтрации коммитов.
+// Pattern - регулярное выражение для message и glob для остальных полей.
+type FilterRule struct {
+	Mode    string // FilterInclude или FilterExclude
+	Field   string // FilterAuthor, FilterMessage, FilterBranch или FilterPath
+	Pattern string
+}
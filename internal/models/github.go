@@ -1,16 +1,94 @@
 package models
 
+import "time"
+
 type Repository struct {
-	Name        string
-	Description string
-	URL         string
-	CreatedAt   string
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	URL           string    `json:"url"`
+	DefaultBranch string    `json:"default_branch,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	PushedAt      time.Time `json:"pushed_at"`
+	Language      string    `json:"language,omitempty"`
+	Fork          bool      `json:"fork,omitempty"`
 }
 
 type Commit struct {
-	SHA     string
-	Message string
-	Author  string
-	Date    string
-	URL     string
+	SHA     string       `json:"sha"`
+	Message string       `json:"message"`
+	Author  string       `json:"author"`
+	Date    time.Time    `json:"date"`
+	URL     string       `json:"url"`
+	Branch  string       `json:"branch,omitempty"`
+	Files   []CommitFile `json:"files,omitempty"`  // заполняется только GetCommit
+	Parent  string       `json:"parent,omitempty"` // первый родитель; заполняется только GetCommit
+
+	Additions int `json:"additions,omitempty"` // заполняются только GetCommit
+	Deletions int `json:"deletions,omitempty"`
+
+	// Коммит не из ветки по умолчанию: такие ветки опрашиваются только ради /filter include branch.
+	NonDefaultBranch bool `json:"non_default_branch,omitempty"`
+
+	Conventional ConventionalCommit `json:"-"`
+}
+
+type CommitFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status"` // added, modified, removed, renamed
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Patch     string `json:"patch,omitempty"`
+
+	// Содержимое файла после и до коммита. Загружается только для манифестов,
+	// которые разбираются целиком (см. deps.NeedsContent).
+	Content         string `json:"-"`
+	PreviousContent string `json:"-"`
+}
+
+type Release struct {
+	Name        string    `json:"name,omitempty"`
+	TagName     string    `json:"tag_name"`
+	URL         string    `json:"url"`
+	Draft       bool      `json:"draft,omitempty"`
+	Prerelease  bool      `json:"prerelease,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Действия с pull request, о которых сообщает бот.
+const (
+	PullRequestOpened = "opened"
+	PullRequestMerged = "merged"
+	PullRequestClosed = "closed" // закрыт без слияния
+)
+
+type PullRequest struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	URL       string    `json:"url"`
+	Base      string    `json:"base,omitempty"` // ветка, в которую предлагаются изменения
+	Draft     bool      `json:"draft,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ClosedAt  time.Time `json:"closed_at,omitempty"`
+	MergedAt  time.Time `json:"merged_at,omitempty"`
+}
+
+// Итог проверок CI коммита.
+const (
+	CIPending = "pending" // часть проверок ещё выполняется
+	CISuccess = "success"
+	CIFailure = "failure"
+)
+
+// CIStatus - итог проверок коммита: check runs (GitHub Actions и другие приложения) и статусы внешних CI.
+type CIStatus struct {
+	SHA     string   `json:"sha"`
+	Branch  string   `json:"branch,omitempty"`
+	Message string   `json:"message,omitempty"` // первая строка сообщения коммита
+	Author  string   `json:"author,omitempty"`
+	URL     string   `json:"url,omitempty"`
+	State   string   `json:"state"`            // CIPending, CISuccess или CIFailure; пусто, если проверок нет
+	Total   int      `json:"total"`            // число проверок
+	Failed  []string `json:"failed,omitempty"` // названия упавших проверок
 }
@@ -0,0 +1,19 @@
+package models
+
+// SecretFinding - найденная в добавленной строке коммита учётная запись, токен или ключ.
+// Snippet уже содержит замаскированный секрет.
+type SecretFinding struct {
+	Rule        string
+	Description string
+	File        string
+	Line        int
+	Snippet     string
+}
+
+// SecretIgnore подавляет ложные срабатывания в репозитории.
+// Пустое правило или путь означают «любое».
+type SecretIgnore struct {
+	Repo string
+	Rule string
+	Path string // glob, как в /filter path
+}
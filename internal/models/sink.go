@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const (
+	SinkSlack   = "slack"
+	SinkDiscord = "discord"
+	SinkWebhook = "webhook"
+)
+
+// SinkKinds перечисляет поддерживаемые внешние получатели уведомлений.
+var SinkKinds = []string{SinkSlack, SinkDiscord, SinkWebhook}
+
+// Sink - дополнительный получатель уведомлений подписки помимо самого чата Telegram.
+type Sink struct {
+	Kind string
+	URL  string // адрес входящего webhook; содержит секрет, поэтому в чат выводится замаскированным
+}
+
+// Validate проверяет получателя до сохранения. Адреса внутренней сети отклоняются сразу,
+// а имена хостов проверяются ещё раз при подключении: DNS может указать куда угодно.
+func (s Sink) Validate() error {
+	known := false
+	for _, kind := range SinkKinds {
+		known = known || s.Kind == kind
+	}
+	if !known {
+		return errors.New("неизвестный тип получателя")
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return errors.New("нужен адрес вида https://...")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("адреса внутренней сети не поддерживаются")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return errors.New("адреса внутренней сети не поддерживаются")
+	}
+	return nil
+}
+
+// Сети, которые не маршрутизируются в интернет, помимо тех, что распознаёт netip.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64 ведёт на любые IPv4-адреса
+}
+
+// IsPublicAddr сообщает, что адрес относится к интернету, а не к локальной машине или внутренней сети:
+// к внешним получателям нельзя подключаться по loopback, частным и link-local адресам.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ID - идентификатор получателя для очередей и журналов доставки: хэш адреса, из которого
+// сам адрес с токеном не восстановить.
+func (s Sink) ID() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s.URL)))
+}
+
+// MaskedURL оставляет от адреса только схему и хост: путь webhook Slack и Discord - это и есть токен.
+func (s Sink) MaskedURL() string {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return "***"
+	}
+	return u.Scheme + "://" + u.Host + "/***"
+}
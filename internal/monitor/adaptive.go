@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const (
+	// Репозиторий с пушем за это время считается активным и проверяется с интервалом пользователя.
+	activeWindow = 24 * time.Hour
+	// Потолок отсрочки для тихого репозитория. Пуш сбрасывает отсрочку сразу:
+	// pushed_at приходит в общем списке репозиториев.
+	maxRepoBackoff = 6 * time.Hour
+	// Потолок отсрочки опроса аккаунта, в котором давно ничего не происходит.
+	maxAccountBackoff = time.Hour
+	jitterFraction    = 0.1
+)
+
+// repoPoll - расписание проверки коммитов одного репозитория.
+type repoPoll struct {
+	pushedAt  time.Time
+	backoff   time.Duration
+	nextCheck time.Time
+}
+
+// needsCheck: репозиторий проверяется после нового пуша или когда истекла его отсрочка.
+func (p *repoPoll) needsCheck(repo models.Repository, now time.Time) bool {
+	return !repo.PushedAt.Equal(p.pushedAt) || !now.Before(p.nextCheck)
+}
+
+// checked пересчитывает отсрочку: активные репозитории проверяются с интервалом пользователя,
+// тихие - всё реже, вдвое за каждую пустую проверку.
+func (p *repoPoll) checked(repo models.Repository, changed bool, interval time.Duration, now time.Time) {
+	p.pushedAt = repo.PushedAt
+
+	if changed || now.Sub(repo.PushedAt) < activeWindow || p.backoff == 0 {
+		p.backoff = interval
+	} else {
+		p.backoff = min(p.backoff*2, max(maxRepoBackoff, interval))
+	}
+	p.nextCheck = now.Add(jitter(p.backoff))
+}
+
+// pollInterval - интервал опроса аккаунта. Пока в аккаунте есть активность, он равен
+// самому короткому интервалу подписчиков; после каждого пустого цикла удваивается до потолка.
+func (s *accountState) pollInterval() time.Duration {
+	interval := s.interval()
+	limit := max(maxAccountBackoff, interval)
+
+	backoff := interval
+	for i := 0; i < s.quietCycles && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return jitter(min(backoff, limit))
+}
+
+func (s *accountState) repoPoll(name string) *repoPoll {
+	p, exists := s.repoPolls[name]
+	if !exists {
+		p = &repoPoll{}
+		s.repoPolls[name] = p
+	}
+	return p
+}
+
+// jitter разносит опросы во времени, удлиняя интервал на случайные 0-10%, чтобы аккаунты
+// не опрашивались одновременно. Интервал только растёт: чаще, чем задал пользователь, опроса не бывает.
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(float64(d)*jitterFraction*rand.Float64())
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"html"
+	"log"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/changelog"
+	"github.com/DragonAirDragon/GO/internal/notify"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+)
+
+const githubRequestTimeout = time.Minute
+
+// sendChangelog строит changelog по запросу /changelog. Args: репозиторий, [от], [до].
+func (m *Manager) sendChangelog(callback telegram.MonitoringCallback) {
+	ctx, cancel := context.WithTimeout(context.Background(), githubRequestTimeout)
+	defer cancel()
+
+	owner, repo := callback.Username, callback.Args[0]
+	name := html.EscapeString(owner + "/" + repo)
+
+	var from, to string
+	if len(callback.Args) > 1 {
+		from = callback.Args[1]
+	}
+	if len(callback.Args) > 2 {
+		to = callback.Args[2]
+	}
+
+	if from == "" {
+		tag, err := m.githubClient.GetLatestTag(ctx, owner, repo)
+		if err != nil {
+			log.Printf("Failed to get tags of %s/%s: %v", owner, repo, err)
+			m.sendMessage(callback.ChatID, "❌ Не удалось получить теги репозитория <b>"+name+"</b>. Проверьте имя репозитория.")
+			return
+		}
+		if tag == "" {
+			m.sendMessage(callback.ChatID, "В репозитории <b>"+name+"</b> нет тегов. Укажите начало явно: /changelog "+html.EscapeString(repo)+" <SHA>")
+			return
+		}
+		from = tag
+	}
+
+	if to == "" {
+		branch, err := m.githubClient.GetDefaultBranch(ctx, owner, repo)
+		if err != nil {
+			log.Printf("Failed to get default branch of %s/%s: %v", owner, repo, err)
+			m.sendMessage(callback.ChatID, "❌ Не удалось получить репозиторий <b>"+name+"</b>.")
+			return
+		}
+		to = branch
+	}
+
+	commits, err := m.githubClient.CompareCommits(ctx, owner, repo, from, to)
+	if err != nil {
+		log.Printf("Failed to compare %s...%s in %s/%s: %v", from, to, owner, repo, err)
+		m.sendMessage(callback.ChatID, "❌ Не удалось сравнить <b>"+html.EscapeString(from)+"</b> и <b>"+
+			html.EscapeString(to)+"</b> в репозитории <b>"+name+"</b>.")
+		return
+	}
+
+	text := changelog.Format(owner+"/"+repo+": "+from+" → "+to, changelog.Build(commits))
+	for _, part := range notify.SplitMessage(text) {
+		m.sendMessage(callback.ChatID, part)
+	}
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"html"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/chart"
+	"github.com/DragonAirDragon/GO/internal/report"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+)
+
+const heatmapWeeks = 26
+
+// sendChart строит график по запросу /chart. Args: вид графика и, для commits, число дней.
+func (m *Manager) sendChart(callback telegram.MonitoringCallback) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	config, _ := m.telegramBot.GetConfig(callback.ChatID)
+	loc := config.Location()
+	account := callback.Username
+	now := time.Now().In(loc)
+
+	var (
+		png     []byte
+		caption string
+		err     error
+	)
+
+	switch callback.Args[0] {
+	case "commits":
+		days, _ := strconv.Atoi(callback.Args[1])
+		since := startOfDay(now).AddDate(0, 0, -(days - 1))
+
+		var counts map[string]int
+		counts, err = report.CommitsByDay(ctx, m.githubClient, account, since, now, loc)
+		if err == nil {
+			png, err = commitsChart("Commits per day: "+account, counts, since, days)
+			caption = "📊 Коммиты <b>" + html.EscapeString(account) + "</b> по дням за " + strconv.Itoa(days) + " дн."
+		}
+
+	case "heatmap":
+		since := startOfDay(now).AddDate(0, 0, -heatmapWeeks*7)
+
+		var counts map[string]int
+		counts, err = report.CommitsByDay(ctx, m.githubClient, account, since, now, loc)
+		if err == nil {
+			png, err = chart.Heatmap("Contributions: "+account, counts, now, heatmapWeeks)
+			caption = "📅 Активность <b>" + html.EscapeString(account) + "</b> за полгода"
+		}
+
+	case "languages":
+		var languages map[string]int
+		languages, err = report.Languages(ctx, m.githubClient, account)
+		if err == nil {
+			var items []chart.Item
+			for language, size := range languages {
+				items = append(items, chart.Item{Label: language, Value: float64(size)})
+			}
+			png, err = chart.Breakdown("Languages: "+account, items)
+			caption = "🧩 Языки в репозиториях <b>" + html.EscapeString(account) + "</b>"
+		}
+	}
+
+	if err != nil {
+		log.Printf("Failed to build %s chart for %s: %v", callback.Args[0], account, err)
+		m.sendMessage(callback.ChatID, "❌ Не удалось построить график для <b>"+html.EscapeString(account)+"</b>.")
+		return
+	}
+
+	m.sendPhoto(callback.ChatID, account, png, caption)
+}
+
+// commitsChart рисует столбцы по дням, начиная с since; дни без коммитов тоже показываются.
+func commitsChart(title string, counts map[string]int, since time.Time, days int) ([]byte, error) {
+	items := make([]chart.Item, 0, days)
+	for i := 0; i < days; i++ {
+		day := since.AddDate(0, 0, i)
+		items = append(items, chart.Item{
+			Label: day.Format("02.01"),
+			Value: float64(counts[day.Format("2006-01-02")]),
+		})
+	}
+	return chart.Bars(title, items)
+}
+
+// weeklyChart строит график коммитов по дням для еженедельного отчёта.
+func weeklyChart(r report.Report, loc *time.Location) ([]byte, error) {
+	counts := make(map[string]int)
+	for _, t := range r.Current.CommitTimes {
+		counts[t.In(loc).Format("2006-01-02")]++
+	}
+	since := startOfDay(r.Current.To.In(loc)).AddDate(0, 0, -6)
+	return commitsChart("Commits per day: "+r.Account, counts, since, 7)
+}
+
+func (m *Manager) sendPhoto(chatID int64, account string, png []byte, caption string) {
+	msg := telegram.OutgoingMessage{
+		ChatID:   chatID,
+		ThreadID: m.telegramBot.ThreadFor(chatID, account, ""),
+		Text:     caption,
+		Photo:    png,
+	}
+	if err := m.telegramBot.Send(msg); err != nil {
+		log.Printf("Failed to queue chart for chat %d: %v", chatID, err)
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Сколько найденных событий перечисляется в ответе на /check.
+const maxCheckLines = 10
+
+// sendCheckResult отвечает на /check. Сами уведомления доставляются как обычно,
+// с учётом сводок, тихих часов и фильтров чата.
+func (m *Manager) sendCheckResult(chatID int64, account string, result pollResult) {
+	if result.err != nil {
+		m.sendMessage(chatID, "❌ Не удалось проверить аккаунт <b>"+html.EscapeString(account)+"</b>. Попробуйте позже.")
+		return
+	}
+
+	incomplete := ""
+	if result.skipped > 0 {
+		incomplete += fmt.Sprintf("\n⏳ Не успели проверить репозиториев: %d, они будут проверены в следующем цикле.", result.skipped)
+	}
+	if result.failed > 0 {
+		incomplete += fmt.Sprintf("\n⚠️ Не удалось проверить репозиториев: %d.", result.failed)
+	}
+
+	if len(result.events) == 0 {
+		if incomplete != "" {
+			m.sendMessage(chatID, "🔍 Проверка <b>"+html.EscapeString(account)+"</b> завершена не полностью, новых событий не найдено."+incomplete)
+			return
+		}
+		m.sendMessage(chatID, "🔍 Проверка <b>"+html.EscapeString(account)+"</b> завершена: изменений нет.")
+		return
+	}
+
+	var lines []string
+	for i, event := range result.events {
+		if i == maxCheckLines {
+			lines = append(lines, fmt.Sprintf("…и ещё %d", len(result.events)-maxCheckLines))
+			break
+		}
+		switch e := event.(type) {
+		case models.RepoCreated:
+			lines = append(lines, "• 🆕 "+html.EscapeString(e.Repo))
+		case models.CommitPushed:
+			summary := strings.SplitN(e.Commit.Message, "\n", 2)[0]
+			lines = append(lines, "• 📝 "+html.EscapeString(e.Repo)+": "+html.EscapeString(summary))
+		case models.ReleasePublished:
+			lines = append(lines, "• 🏷 "+html.EscapeString(e.Repo)+": "+html.EscapeString(e.Release.TagName))
+		}
+	}
+
+	m.sendMessage(chatID, fmt.Sprintf("🔍 Проверка <b>%s</b> завершена, найдено событий: %d\n%s\n%s\n"+
+		"Уведомления доставляются с учётом настроек чата (сводки, тихие часы, фильтры).",
+		html.EscapeString(account), len(result.events), strings.Join(lines, "\n"), incomplete))
+}
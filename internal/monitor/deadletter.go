@@ -0,0 +1,285 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+)
+
+const (
+	storeTimeout = 10 * time.Second
+
+	// Сколько недоставленных сообщений показывает /deadletters и переотправляет /deadletters replay all.
+	deadLetterListLimit   = 20
+	deadLetterReplayLimit = 1000
+	deadLetterPreview     = 1000 // символов текста в /deadletters show
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrDeadLetterReplayed = errors.New("dead letter is already replayed")
+)
+
+// recordFailure сохраняет окончательно недоставленное сообщение, чтобы администратор мог разобраться и переотправить его.
+func (m *Manager) recordFailure(failure telegram.DeliveryFailure) {
+	msg := failure.Message
+	log.Printf("Message to chat %d is undeliverable after %d attempts: %v", msg.ChatID, failure.Attempts, failure.Err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	letter := models.DeadLetter{
+		Channel:  models.DeadLetterTelegram,
+		ChatID:   msg.ChatID,
+		ThreadID: msg.ThreadID,
+		Text:     msg.Text,
+		Silent:   msg.Silent,
+		Photo:    msg.Photo,
+		Error:    failure.Err.Error(),
+		Attempts: failure.Attempts,
+		FailedAt: time.Now(),
+	}
+	if err := m.store.AddDeadLetter(ctx, letter); err != nil {
+		log.Printf("Failed to record undeliverable message for chat %d: %v", msg.ChatID, err)
+	}
+}
+
+// DeadLetters возвращает недоставленные сообщения, новые первыми.
+func (m *Manager) DeadLetters(ctx context.Context, limit int) ([]models.DeadLetter, error) {
+	return m.store.ListDeadLetters(ctx, limit)
+}
+
+func (m *Manager) DeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	letter, err := m.store.GetDeadLetter(ctx, id)
+	if err == nil && letter == nil {
+		err = ErrDeadLetterNotFound
+	}
+	return letter, err
+}
+
+// ReplayDeadLetter ставит сообщение в очередь отправки заново. Сообщение сначала атомарно
+// отмечается переотправленным, поэтому одновременные вызовы не отправят его дважды;
+// если поставить его в очередь не удалось, отметка снимается. Если сообщение снова не дойдёт,
+// в очереди недоставленных появится новая запись.
+func (m *Manager) ReplayDeadLetter(ctx context.Context, id int64) error {
+	letter, err := m.store.ClaimDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+	if letter == nil {
+		if _, err := m.DeadLetter(ctx, id); err != nil {
+			return err
+		}
+		return ErrDeadLetterReplayed
+	}
+
+	if err := m.replay(ctx, *letter); err != nil {
+		if releaseErr := m.store.ReleaseDeadLetter(ctx, id); releaseErr != nil {
+			log.Printf("Failed to release dead letter %d: %v", id, releaseErr)
+		}
+		return err
+	}
+
+	log.Printf("Replayed undeliverable %s message %d for chat %d", letter.Channel, id, letter.ChatID)
+	return nil
+}
+
+func (m *Manager) replay(ctx context.Context, letter models.DeadLetter) error {
+	switch letter.Channel {
+	case models.DeadLetterEvent:
+		eventID, err := strconv.ParseInt(letter.Target, 10, 64)
+		if err != nil {
+			return fmt.Errorf("dead letter %d has malformed event ID %q", letter.ID, letter.Target)
+		}
+		if err := m.store.RetryEvent(ctx, eventID); err != nil {
+			return err
+		}
+		m.wakeOutbox()
+		return nil
+
+	case models.DeadLetterSink, models.DeadLetterEmail:
+		return m.dispatcher.Replay(letter)
+	}
+
+	return m.telegramBot.Requeue(telegram.OutgoingMessage{
+		ChatID:   letter.ChatID,
+		ThreadID: letter.ThreadID,
+		Text:     letter.Text,
+		Silent:   letter.Silent,
+		Photo:    letter.Photo,
+	})
+}
+
+func (m *Manager) DeleteDeadLetter(ctx context.Context, id int64) error {
+	if _, err := m.DeadLetter(ctx, id); err != nil {
+		return err
+	}
+	return m.store.DeleteDeadLetter(ctx, id)
+}
+
+// handleDeadLetters отвечает администратору на /deadletters. Args: [list] | show <id> | replay <id|all> | drop <id>.
+func (m *Manager) handleDeadLetters(callback telegram.MonitoringCallback) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	chatID := callback.ChatID
+	args := callback.Args
+	if len(args) == 0 || args[0] == "list" {
+		m.sendDeadLetters(ctx, chatID)
+		return
+	}
+
+	if args[0] == "replay" && len(args) > 1 && args[1] == "all" {
+		m.replayAllDeadLetters(ctx, chatID)
+		return
+	}
+
+	if len(args) < 2 {
+		m.sendMessage(chatID, deadLettersUsage)
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(args[1], "#"), 10, 64)
+	if err != nil {
+		m.sendMessage(chatID, deadLettersUsage)
+		return
+	}
+
+	switch args[0] {
+	case "show":
+		letter, err := m.DeadLetter(ctx, id)
+		if err != nil {
+			m.sendMessage(chatID, deadLetterError(id, err))
+			return
+		}
+		m.sendMessage(chatID, m.formatDeadLetter(chatID, *letter))
+
+	case "replay":
+		if err := m.ReplayDeadLetter(ctx, id); err != nil {
+			m.sendMessage(chatID, deadLetterError(id, err))
+			return
+		}
+		m.sendMessage(chatID, fmt.Sprintf("Сообщение #%d поставлено в очередь отправки.", id))
+
+	case "drop":
+		if err := m.DeleteDeadLetter(ctx, id); err != nil {
+			m.sendMessage(chatID, deadLetterError(id, err))
+			return
+		}
+		m.sendMessage(chatID, fmt.Sprintf("Сообщение #%d удалено.", id))
+
+	default:
+		m.sendMessage(chatID, deadLettersUsage)
+	}
+}
+
+const deadLettersUsage = "Недоставленные сообщения:\n" +
+	"/deadletters - последние записи\n" +
+	"/deadletters show <id> - ошибка и текст сообщения\n" +
+	"/deadletters replay <id>|all - отправить заново\n" +
+	"/deadletters drop <id> - удалить запись"
+
+func (m *Manager) sendDeadLetters(ctx context.Context, chatID int64) {
+	letters, err := m.DeadLetters(ctx, deadLetterListLimit)
+	if err != nil {
+		log.Printf("Failed to list dead letters: %v", err)
+		m.sendMessage(chatID, "❌ Не удалось загрузить недоставленные сообщения.")
+		return
+	}
+	if len(letters) == 0 {
+		m.sendMessage(chatID, "Недоставленных сообщений нет.")
+		return
+	}
+
+	config, _ := m.telegramBot.GetConfig(chatID)
+	lines := []string{"📭 Недоставленные сообщения:"}
+	for _, letter := range letters {
+		lines = append(lines, fmt.Sprintf("#%d · %s · %s\n  %s", letter.ID, deadLetterSource(letter),
+			config.FormatTime(letter.FailedAt), html.EscapeString(truncateText(letter.Error, 100))))
+	}
+	lines = append(lines, "", "Подробнее: /deadletters show <id>, отправить заново: /deadletters replay <id>|all")
+	m.sendMessage(chatID, strings.Join(lines, "\n"))
+}
+
+func (m *Manager) replayAllDeadLetters(ctx context.Context, chatID int64) {
+	letters, err := m.DeadLetters(ctx, deadLetterReplayLimit)
+	if err != nil {
+		log.Printf("Failed to list dead letters: %v", err)
+		m.sendMessage(chatID, "❌ Не удалось загрузить недоставленные сообщения.")
+		return
+	}
+
+	replayed := 0
+	for _, letter := range letters {
+		if err := m.ReplayDeadLetter(ctx, letter.ID); err != nil {
+			log.Printf("Failed to replay dead letter %d: %v", letter.ID, err)
+			continue
+		}
+		replayed++
+	}
+	m.sendMessage(chatID, fmt.Sprintf("Поставлено в очередь отправки: %d из %d.", replayed, len(letters)))
+}
+
+func (m *Manager) formatDeadLetter(chatID int64, letter models.DeadLetter) string {
+	config, _ := m.telegramBot.GetConfig(chatID)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📭 Сообщение #%d\n", letter.ID)
+	fmt.Fprintf(&b, "• Откуда: %s", deadLetterSource(letter))
+	if letter.ThreadID != 0 {
+		fmt.Fprintf(&b, ", тема %d", letter.ThreadID)
+	}
+	b.WriteString("\n")
+	if letter.Target != "" && letter.Channel != models.DeadLetterEvent {
+		fmt.Fprintf(&b, "• Получатель: <code>%s</code>\n", html.EscapeString(letter.Target))
+	}
+	fmt.Fprintf(&b, "• Ошибка: %s\n", html.EscapeString(letter.Error))
+	fmt.Fprintf(&b, "• Попыток: %d, последняя %s\n", letter.Attempts, config.FormatTime(letter.FailedAt))
+	if letter.Photo != nil {
+		b.WriteString("• С графиком\n")
+	}
+	if letter.ReplayedAt != nil {
+		b.WriteString("• Переотправлено " + config.FormatTime(*letter.ReplayedAt) + "\n")
+	}
+	b.WriteString("\n<pre>" + html.EscapeString(truncateText(letter.Text, deadLetterPreview)) + "</pre>")
+	return b.String()
+}
+
+// deadLetterSource описывает, куда не дошло сообщение.
+func deadLetterSource(letter models.DeadLetter) string {
+	switch letter.Channel {
+	case models.DeadLetterSink:
+		return fmt.Sprintf("/sink чата %d", letter.ChatID)
+	case models.DeadLetterEmail:
+		return fmt.Sprintf("почта чата %d", letter.ChatID)
+	case models.DeadLetterEvent:
+		return "событие outbox #" + letter.Target
+	}
+	return fmt.Sprintf("чат %d", letter.ChatID)
+}
+
+func deadLetterError(id int64, err error) string {
+	switch {
+	case errors.Is(err, ErrDeadLetterNotFound):
+		return fmt.Sprintf("Сообщения #%d нет.", id)
+	case errors.Is(err, ErrDeadLetterReplayed):
+		return fmt.Sprintf("Сообщение #%d уже переотправлено.", id)
+	}
+	log.Printf("Dead letter %d: %v", id, err)
+	return fmt.Sprintf("❌ Не удалось обработать сообщение #%d: %s", id, html.EscapeString(err.Error()))
+}
+
+func truncateText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/DragonAirDragon/GO/internal/events"
+	"github.com/DragonAirDragon/GO/internal/github"
+	"github.com/DragonAirDragon/GO/internal/notify"
+	"github.com/DragonAirDragon/GO/internal/storage"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+)
+
+type Manager struct {
+	githubClient *github.Client
+	telegramBot  *telegram.Bot
+	dispatcher   *notify.Dispatcher
+	bus          *events.Bus
+	store        storage.DeliveryStore
+	outboxWake   chan struct{}
+	commits      commitCache // коммиты с файлами для подписчиков шины
+
+	// Состояние подписок принадлежит горутине планировщика и меняется только через control.
+	accounts map[string]*accountState // ключ - имя аккаунта в нижнем регистре
+	chats    map[int64]string         // чат -> ключ аккаунта, на который он подписан
+	control  chan controlMessage
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewManager создаёт менеджер мониторинга. store хранит состояние аккаунтов, outbox событий,
+// отложенные уведомления и недоставленные сообщения; mailer может быть nil - тогда сводки по почте не отправляются.
+func NewManager(githubClient *github.Client, telegramBot *telegram.Bot, store storage.DeliveryStore, mailer *notify.Mailer) *Manager {
+	m := &Manager{
+		githubClient: githubClient,
+		telegramBot:  telegramBot,
+		dispatcher:   notify.NewDispatcher(telegramBot, mailer, store),
+		bus:          events.NewBus(),
+		store:        store,
+		outboxWake:   make(chan struct{}, 1),
+		accounts:     make(map[string]*accountState),
+		chats:        make(map[int64]string),
+		control:      make(chan controlMessage, 100),
+	}
+	m.subscribeHandlers()
+	telegramBot.SetFailureHandler(m.recordFailure)
+	if mailer != nil {
+		telegramBot.SetEmailSender(m.dispatcher.SendConfirmation)
+	}
+	return m
+}
+
+// Run обрабатывает команды бота, пока не закроется канал колбэков.
+func (m *Manager) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	go m.dispatcher.Run(ctx)
+	go m.runReports(ctx)
+	go m.runScheduler(ctx)
+	go m.runOutbox(ctx)
+
+	for callback := range m.telegramBot.GetCallbackChannel() {
+		log.Printf("Received callback: %s for chat %d, username: %s", callback.Type, callback.ChatID, callback.Username)
+		m.handleCallback(ctx, callback)
+	}
+}
+
+func (m *Manager) handleCallback(ctx context.Context, callback telegram.MonitoringCallback) {
+	switch callback.Type {
+	case "changelog":
+		go m.sendChangelog(callback)
+
+	case "report":
+		go m.sendReport(callback)
+
+	case "chart":
+		go m.sendChart(callback)
+
+	case "deadletters":
+		go m.handleDeadLetters(callback)
+
+	case "start":
+		m.sendControl(ctx, controlMessage{op: opSubscribe, chatID: callback.ChatID, username: callback.Username, interval: callback.Interval})
+
+	case "stop":
+		m.sendControl(ctx, controlMessage{op: opUnsubscribe, chatID: callback.ChatID})
+
+	case "migrate":
+		m.dispatcher.Migrate(ctx, callback.ChatID, callback.NewChatID)
+		m.sendControl(ctx, controlMessage{op: opMigrate, chatID: callback.ChatID, newChatID: callback.NewChatID})
+
+	case "update":
+		m.sendControl(ctx, controlMessage{op: opSetInterval, chatID: callback.ChatID, interval: callback.Interval})
+
+	case "pause":
+		m.sendControl(ctx, controlMessage{op: opPause, chatID: callback.ChatID})
+
+	case "resume":
+		m.sendControl(ctx, controlMessage{op: opResume, chatID: callback.ChatID})
+
+	case "check":
+		m.sendControl(ctx, controlMessage{op: opCheckNow, chatID: callback.ChatID})
+	}
+}
+
+// sendControl передаёт команду планировщику. После Shutdown планировщик не читает control,
+// и команда отбрасывается, а не блокирует обработку колбэков.
+func (m *Manager) sendControl(ctx context.Context, msg controlMessage) {
+	select {
+	case m.control <- msg:
+	case <-ctx.Done():
+		log.Printf("Monitoring is stopped, dropping command for chat %d", msg.chatID)
+	}
+}
+
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	log.Println("Monitoring scheduler stopped")
+}
@@ -0,0 +1,325 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/filter"
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Сохранённое состояние старше этого срока не восстанавливается: за долгий простой накопилось бы
+// слишком много старых событий, и аккаунт загружается заново, как при первой подписке.
+const maxSnapshotAge = 24 * time.Hour
+
+// initAccount восстанавливает сохранённое состояние аккаунта или загружает текущее,
+// чтобы уведомлять только о новых событиях. restored означает, что состояние восстановлено
+// и нужно сразу проверить события, пропущенные, пока бот не работал.
+func (m *Manager) initAccount(ctx context.Context, state *accountState, interval time.Duration) (restored bool, err error) {
+	username := state.name
+
+	snapshot, err := m.store.LoadAccount(ctx, username)
+	if err != nil {
+		log.Printf("Failed to load saved state of %s: %v", username, err)
+	} else if snapshot != nil && snapshot.Repos != nil && time.Since(snapshot.UpdatedAt) < maxSnapshotAge {
+		state.known = *snapshot
+		log.Printf("Restored state of %s: %d repositories", username, len(snapshot.Repos))
+		return true, nil
+	}
+
+	repos, err := m.githubClient.GetRepositories(ctx, username)
+	if err != nil {
+		log.Printf("Failed to get initial repositories for %s: %v", username, err)
+		return false, err
+	}
+
+	now := time.Now()
+	known := models.NewAccountSnapshot()
+	opts := fetchOptions{branches: m.branchPatterns(username)}
+	for i, result := range m.fetchRepos(ctx, username, repos, opts) {
+		repo := models.RepoSnapshot{LastRelease: now, LastPullRequest: now}
+		state.repoPoll(repos[i].Name).checked(repos[i], false, interval, now)
+
+		if result.err != nil {
+			log.Printf("Failed to get commits for %s: %v", repos[i].Name, result.err)
+		} else {
+			if result.commit != nil {
+				repo.LastCommit = result.commit.SHA
+				repo.CICommit = result.commit.SHA
+			}
+			repo.Branches = result.branches
+		}
+		known.Repos[repos[i].Name] = repo
+	}
+
+	known.UpdatedAt = now
+	if err := m.store.SavePoll(ctx, username, known, nil); err != nil {
+		log.Printf("Failed to save state of %s: %v", username, err)
+	}
+	state.known = known
+
+	log.Printf("Started monitoring GitHub account: %s", username)
+	log.Printf("Initial state: %d repositories", len(repos))
+	return false, nil
+}
+
+type pollResult struct {
+	active  bool // в цикле были события или свежие пуши
+	events  []models.Event
+	skipped int // репозитории, до которых не дошла очередь до истечения интервала
+	failed  int // репозитории, которые не удалось загрузить
+	err     error
+}
+
+// checkedRepo - проверенный репозиторий, расписание которого обновляется после сохранения опроса.
+type checkedRepo struct {
+	repo    models.Repository
+	changed bool
+}
+
+// poll ищет в аккаунте новые репозитории, коммиты, релизы, pull request и итоги CI и сохраняет их в outbox
+// одной транзакцией с новым состоянием аккаунта; доставляет их runOutbox.
+// Коммиты, релизы и pull request запрашиваются только у репозиториев с новым пушем или истёкшей отсрочкой (см. repoPoll).
+// Загрузка ограничена интервалом, чтобы медленный цикл не наезжал на следующий;
+// репозитории, до которых не дошла очередь, проверяются в следующем цикле.
+// force заставляет проверить все репозитории, не дожидаясь отсрочки (/check).
+func (m *Manager) poll(ctx context.Context, state *accountState, interval time.Duration, force bool) pollResult {
+	username := state.name
+
+	fetchCtx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+
+	currentRepos, err := m.githubClient.GetRepositories(fetchCtx, username)
+	if err != nil {
+		log.Printf("Failed to get repositories for %s: %v", username, err)
+		return pollResult{err: err}
+	}
+
+	// Состояние меняется в копии и заменяется, только когда события сохранены в outbox.
+	known := state.known.Clone()
+	var detected []models.Event
+	now := time.Now()
+	active := false
+	source := func(repo string) models.EventSource {
+		return models.EventSource{Account: username, Repo: repo, DetectedAt: now}
+	}
+
+	var due []models.Repository
+	for _, repo := range currentRepos {
+		if _, exists := known.Repos[repo.Name]; !exists {
+			known.Repos[repo.Name] = models.RepoSnapshot{LastRelease: now, LastPullRequest: now}
+			detected = append(detected, models.RepoCreated{EventSource: source(repo.Name), Repository: repo})
+		}
+		if now.Sub(repo.PushedAt) < activeWindow {
+			active = true
+		}
+		if force || state.repoPoll(repo.Name).needsCheck(repo, now) {
+			due = append(due, repo)
+		}
+	}
+
+	opts := fetchOptions{
+		releases:      m.wants(username, models.EventRelease),
+		pullRequests:  m.wants(username, models.EventPullRequest),
+		ci:            m.wants(username, models.EventCI),
+		knownCI:       make(map[string]string, len(due)),
+		branches:      m.branchPatterns(username),
+		knownBranches: make(map[string]map[string]string, len(due)),
+	}
+	for _, repo := range due {
+		opts.knownCI[repo.Name] = known.Repos[repo.Name].CICommit
+		opts.knownBranches[repo.Name] = known.Repos[repo.Name].Branches
+	}
+
+	var checked []checkedRepo
+	skipped, failed := 0, 0
+	for i, result := range m.fetchRepos(fetchCtx, username, due, opts) {
+		repo := due[i]
+		if result.err != nil {
+			if fetchCtx.Err() != nil {
+				skipped++
+			} else {
+				failed++
+				log.Printf("Failed to get commits for %s: %v", repo.Name, result.err)
+			}
+			continue
+		}
+
+		snapshot := known.Repos[repo.Name]
+		changed := false
+		pushed := result.commit != nil && snapshot.LastCommit != result.commit.SHA
+		if pushed {
+			detected = append(detected, models.CommitPushed{EventSource: source(repo.Name), Commit: *result.commit, Previous: snapshot.LastCommit})
+			snapshot.LastCommit = result.commit.SHA
+			changed = true
+		}
+		for _, pushed := range result.branchCommits {
+			detected = append(detected, models.CommitPushed{EventSource: source(repo.Name), Commit: pushed.commit, Previous: pushed.previous})
+			changed = true
+		}
+		snapshot.Branches = result.branches
+
+		switch {
+		case !opts.releases:
+			// Релизы никому не нужны; если их включат, уведомления придут только о новых.
+			snapshot.LastRelease = now
+		case result.releasesErr != nil:
+			// LastRelease не сдвигается: релизы проверятся в следующем цикле.
+			log.Printf("Failed to get releases for %s: %v", repo.Name, result.releasesErr)
+		}
+
+		// GitHub отдаёт релизы от новых к старым, события сохраняются в хронологическом порядке.
+		for j := len(result.releases) - 1; j >= 0; j-- {
+			release := result.releases[j]
+			if release.Draft || !release.PublishedAt.After(snapshot.LastRelease) {
+				continue
+			}
+			detected = append(detected, models.ReleasePublished{EventSource: source(repo.Name), Release: release})
+			snapshot.LastRelease = release.PublishedAt
+			changed = true
+		}
+
+		switch {
+		case !opts.pullRequests || snapshot.LastPullRequest.IsZero():
+			// PR никому не нужны или ещё не проверялись: уведомления придут только о новых изменениях.
+			snapshot.LastPullRequest = now
+		case result.pullRequestsErr != nil:
+			log.Printf("Failed to get pull requests for %s: %v", repo.Name, result.pullRequestsErr)
+		default:
+			// GitHub отдаёт PR от недавно изменённых к давним, события сохраняются в хронологическом порядке.
+			since := snapshot.LastPullRequest
+			for j := len(result.pullRequests) - 1; j >= 0; j-- {
+				pull := result.pullRequests[j]
+				if !pull.UpdatedAt.After(since) {
+					continue
+				}
+				if action := pullRequestAction(pull, since); action != "" {
+					detected = append(detected, models.PullRequestChanged{EventSource: source(repo.Name), Action: action, PullRequest: pull})
+					changed = true
+				}
+				if pull.UpdatedAt.After(snapshot.LastPullRequest) {
+					snapshot.LastPullRequest = pull.UpdatedAt
+				}
+			}
+		}
+
+		if result.commit != nil {
+			head := result.commit
+			switch {
+			case !opts.ci || snapshot.CICommit == "":
+				// Как и с релизами: если CI включат, уведомления придут только о следующих коммитах.
+				snapshot.CICommit = head.SHA
+			case result.ciErr != nil:
+				log.Printf("Failed to get CI status of %s in %s: %v", head.SHA, repo.Name, result.ciErr)
+			case result.ci == nil || result.ci.State == models.CIPending:
+				// Итог уже известен или проверки ещё идут.
+			case result.ci.State == "":
+				// Сразу после пуша проверки могут ещё не появиться; коммит без проверок пропускается со следующего цикла.
+				if !pushed {
+					snapshot.CICommit = head.SHA
+				}
+			default:
+				status := *result.ci
+				status.Branch = head.Branch
+				status.Message = strings.SplitN(head.Message, "\n", 2)[0]
+				status.Author = head.Author
+				status.URL = repo.URL + "/commit/" + head.SHA + "/checks"
+				detected = append(detected, models.CICompleted{EventSource: source(repo.Name), Status: status})
+				snapshot.CICommit = head.SHA
+				changed = true
+			}
+		}
+
+		known.Repos[repo.Name] = snapshot
+		checked = append(checked, checkedRepo{repo: repo, changed: changed})
+	}
+	if skipped > 0 {
+		log.Printf("Polling %s hit the %s deadline, %d repositories postponed to the next cycle", username, interval, skipped)
+	}
+
+	known.UpdatedAt = now
+	if err := m.store.SavePoll(ctx, username, known, detected); err != nil {
+		// Состояние не меняется: те же события будут найдены в следующем цикле.
+		log.Printf("Failed to save poll of %s: %v", username, err)
+		return pollResult{err: err}
+	}
+
+	state.known = known
+	for _, c := range checked {
+		state.repoPoll(c.repo.Name).checked(c.repo, c.changed, interval, now)
+	}
+	if len(detected) > 0 {
+		m.wakeOutbox()
+	}
+	return pollResult{active: active || len(detected) > 0, events: detected, skipped: skipped, failed: failed}
+}
+
+// wants сообщает, включён ли тип событий хотя бы у одного подписчика аккаунта:
+// иначе запросы релизов, pull request и CI умножали бы число обращений к API впустую.
+func (m *Manager) wants(account string, eventType models.EventType) bool {
+	for _, chatID := range m.recipients(account) {
+		if config, _ := m.telegramBot.GetConfig(chatID); config.Wants(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// pullRequestAction определяет, что случилось с pull request после since; пусто, если PR только
+// обновили (комментарий, новый коммит) - об этом бот не сообщает. PR, открытый и влитый
+// между проверками, приходит одним событием о слиянии.
+func pullRequestAction(pull models.PullRequest, since time.Time) string {
+	switch {
+	case pull.MergedAt.After(since):
+		return models.PullRequestMerged
+	case pull.ClosedAt.After(since):
+		return models.PullRequestClosed
+	case pull.CreatedAt.After(since) && pull.ClosedAt.IsZero():
+		return models.PullRequestOpened
+	}
+	return ""
+}
+
+// branchPatterns собирает шаблоны /filter include branch всех подписчиков аккаунта:
+// кроме ветки по умолчанию, опрашиваются только эти ветки.
+func (m *Manager) branchPatterns(account string) []string {
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, chatID := range m.recipients(account) {
+		config, _ := m.telegramBot.GetConfig(chatID)
+		for _, pattern := range filter.BranchPatterns(config.Filters) {
+			if !seen[pattern] {
+				seen[pattern] = true
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// recipients возвращает чаты, которые следят за аккаунтом и не поставили уведомления на паузу.
+func (m *Manager) recipients(account string) []int64 {
+	var chats []int64
+	for _, chatID := range m.telegramBot.ActiveChats() {
+		config, _ := m.telegramBot.GetConfig(chatID)
+		if !config.Paused && strings.EqualFold(config.GitHubUsername, account) {
+			chats = append(chats, chatID)
+		}
+	}
+	return chats
+}
+
+func (m *Manager) sendStarted(chatID int64, username string, repoCount, interval int) {
+	m.sendMessage(chatID, fmt.Sprintf("✅ Мониторинг GitHub аккаунта <b>%s</b> запущен!\n"+
+		"Найдено репозиториев: %d\n"+
+		"Интервал проверки: %d минут", username, repoCount, interval))
+}
+
+func (m *Manager) sendMessage(chatID int64, text string) {
+	if err := m.telegramBot.SendMessage(chatID, text); err != nil {
+		log.Printf("Failed to queue message for chat %d: %v", chatID, err)
+	}
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/storage"
+)
+
+const (
+	outboxInterval = 5 * time.Second
+	outboxBatch    = 100
+
+	// Неудачная доставка повторяется с удвоением паузы; после maxOutboxAttempts попыток событие откладывается.
+	outboxRetryBase   = 30 * time.Second
+	outboxRetryMax    = time.Hour
+	maxOutboxAttempts = 10
+
+	// Сколько хранятся доставленные события.
+	outboxRetention = 7 * 24 * time.Hour
+	outboxPruneEach = time.Hour
+)
+
+// runOutbox доставляет события из outbox подписчикам шины: сразу после опроса, нашедшего события,
+// и периодически - для повторов и событий, оставшихся от прошлого запуска.
+func (m *Manager) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		m.deliverPending(ctx)
+
+		if time.Since(lastPrune) >= outboxPruneEach {
+			lastPrune = time.Now()
+			if err := m.store.PruneDelivered(ctx, lastPrune.Add(-outboxRetention)); err != nil {
+				log.Printf("Failed to prune outbox: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.outboxWake:
+		}
+	}
+}
+
+// wakeOutbox будит runOutbox, не дожидаясь тика.
+func (m *Manager) wakeOutbox() {
+	select {
+	case m.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) deliverPending(ctx context.Context) {
+	for {
+		pending, err := m.store.PendingEvents(ctx, time.Now(), outboxBatch)
+		if err != nil {
+			log.Printf("Failed to load outbox: %v", err)
+			return
+		}
+
+		for _, record := range pending {
+			deliveries, err := m.newDeliveryLog(ctx, record.ID)
+			if err != nil {
+				log.Printf("Failed to load deliveries of event %d: %v", record.ID, err)
+				return
+			}
+			if err := m.bus.Publish(withDeliveryLog(ctx, deliveries), record.Event); err != nil {
+				if ctx.Err() != nil {
+					// Бот останавливается: событие останется в outbox до следующего запуска.
+					return
+				}
+				m.deliveryFailed(ctx, record, err)
+				continue
+			}
+			if err := m.store.MarkDelivered(ctx, record.ID); err != nil {
+				log.Printf("Failed to mark event %d delivered: %v", record.ID, err)
+				return
+			}
+		}
+
+		if len(pending) < outboxBatch {
+			return
+		}
+	}
+}
+
+func (m *Manager) deliveryFailed(ctx context.Context, record storage.OutboxEvent, err error) {
+	source := record.Event.Source()
+
+	var retryAt time.Time
+	if record.Attempts+1 < maxOutboxAttempts {
+		retryAt = time.Now().Add(min(outboxRetryBase<<record.Attempts, outboxRetryMax))
+		log.Printf("Failed to deliver %s event of %s/%s, retrying at %s: %v",
+			record.Event.Kind(), source.Account, source.Repo, retryAt.Format(time.RFC3339), err)
+	} else {
+		log.Printf("Giving up on %s event of %s/%s after %d attempts: %v",
+			record.Event.Kind(), source.Account, source.Repo, maxOutboxAttempts, err)
+	}
+
+	if err := m.store.MarkFailed(ctx, record.ID, err.Error(), retryAt); err != nil {
+		log.Printf("Failed to record failed delivery of event %d: %v", record.ID, err)
+		return
+	}
+
+	// Отложенное событие попадает в очередь недоставленных: /deadletters replay вернёт его в outbox,
+	// и оно дойдёт до тех получателей, которым не дошло.
+	if retryAt.IsZero() {
+		letter := models.DeadLetter{
+			Channel:  models.DeadLetterEvent,
+			Target:   strconv.FormatInt(record.ID, 10),
+			Text:     fmt.Sprintf("%s %s/%s", record.Event.Kind(), source.Account, source.Repo),
+			Error:    err.Error(),
+			Attempts: maxOutboxAttempts,
+			FailedAt: time.Now(),
+		}
+		if err := m.store.AddDeadLetter(ctx, letter); err != nil {
+			log.Printf("Failed to record undeliverable event %d: %v", record.ID, err)
+		}
+	}
+}
+
+// deliveryLog помнит, каким получателям событие уже доставлено. Если часть получателей
+// вернула ошибку, outbox повторяет событие только для остальных.
+type deliveryLog struct {
+	store   storage.EventStore
+	eventID int64
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+type deliveryLogKey struct{}
+
+func (m *Manager) newDeliveryLog(ctx context.Context, eventID int64) (*deliveryLog, error) {
+	recipients, err := m.store.DeliveredTo(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	l := &deliveryLog{store: m.store, eventID: eventID, done: make(map[string]bool, len(recipients))}
+	for _, recipient := range recipients {
+		l.done[recipient] = true
+	}
+	return l, nil
+}
+
+func withDeliveryLog(ctx context.Context, l *deliveryLog) context.Context {
+	return context.WithValue(ctx, deliveryLogKey{}, l)
+}
+
+// deliverOnce вызывает deliver, если событие ещё не доставлено получателю recipient, и запоминает успех.
+// Вне outbox (без журнала в контексте) deliver просто вызывается.
+func deliverOnce(ctx context.Context, recipient string, deliver func() error) error {
+	l, _ := ctx.Value(deliveryLogKey{}).(*deliveryLog)
+	if l == nil {
+		return deliver()
+	}
+
+	l.mu.Lock()
+	done := l.done[recipient]
+	l.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	if err := deliver(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.done[recipient] = true
+	l.mu.Unlock()
+	return l.store.MarkDeliveredTo(ctx, l.eventID, recipient)
+}
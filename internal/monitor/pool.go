@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/DragonAirDragon/GO/internal/filter"
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Сколько репозиториев одного аккаунта загружается одновременно.
+const maxFetchWorkers = 8
+
+// Сколько последних релизов запрашивается при проверке репозитория.
+const releasesPerCheck = 5
+
+// Сколько недавно изменённых pull request запрашивается при проверке репозитория.
+const pullRequestsPerCheck = 10
+
+type repoResult struct {
+	commit        *models.Commit    // nil, если в репозитории нет коммитов
+	branches      map[string]string // последние коммиты отслеживаемых веток, кроме ветки по умолчанию
+	branchCommits []branchCommit    // новые коммиты в этих ветках
+	releases      []models.Release
+	pullRequests  []models.PullRequest
+	ci            *models.CIStatus // nil, если CI не запрашивался
+	err           error            // ошибка загрузки коммитов
+	// Релизы, pull request или CI не загрузились, коммиты при этом обрабатываются.
+	releasesErr     error
+	pullRequestsErr error
+	ciErr           error
+}
+
+// branchCommit - новый последний коммит ветки и прошлый известный коммит этой ветки.
+type branchCommit struct {
+	commit   models.Commit
+	previous string
+}
+
+// fetchOptions - что загружать, кроме последнего коммита ветки по умолчанию.
+type fetchOptions struct {
+	// Релизы, pull request и CI запрашиваются, только если их ждёт хотя бы один подписчик.
+	releases     bool
+	pullRequests bool
+	ci           bool
+	// Коммиты ветки по умолчанию, о CI которых уже известно, по репозиториям: CI запрашивается,
+	// пока у последнего коммита нет итога.
+	knownCI map[string]string
+	// Шаблоны веток из /filter include branch и известные последние коммиты этих веток
+	// по репозиториям: новая ветка только запоминается, о коммитах сообщается со следующего пуша.
+	branches      []string
+	knownBranches map[string]map[string]string
+}
+
+// fetchRepos параллельно загружает последний коммит каждого репозитория, а по opts - релизы,
+// pull request, CI и отслеживаемые ветки. results[i] соответствует repos[i], поэтому порядок событий
+// не зависит от того, какой запрос завершился первым. После отмены ctx оставшиеся репозитории не запрашиваются.
+func (m *Manager) fetchRepos(ctx context.Context, username string, repos []models.Repository, opts fetchOptions) []repoResult {
+	results := make([]repoResult, len(repos))
+
+	forEach(ctx, len(repos), maxFetchWorkers, func(i int) {
+		commits, err := m.githubClient.GetLatestCommit(ctx, username, repos[i].Name, "")
+		if err != nil {
+			results[i].err = err
+			return
+		}
+		if len(commits) > 0 {
+			commit := commits[0]
+			commit.Branch = repos[i].DefaultBranch
+			results[i].commit = &commit
+		}
+
+		if len(opts.branches) > 0 {
+			if err := m.fetchBranches(ctx, username, repos[i], opts, &results[i]); err != nil {
+				results[i].err = err
+				return
+			}
+		}
+
+		if opts.releases {
+			results[i].releases, results[i].releasesErr = m.githubClient.GetReleases(ctx, username, repos[i].Name, releasesPerCheck)
+		}
+
+		if opts.pullRequests {
+			results[i].pullRequests, results[i].pullRequestsErr = m.githubClient.GetPullRequests(ctx, username, repos[i].Name, pullRequestsPerCheck)
+		}
+
+		if opts.ci && results[i].commit != nil {
+			if known := opts.knownCI[repos[i].Name]; known != "" && known != results[i].commit.SHA {
+				results[i].ci, results[i].ciErr = m.githubClient.GetCIStatus(ctx, username, repos[i].Name, results[i].commit.SHA)
+			}
+		}
+	}, func(i int) {
+		results[i].err = ctx.Err()
+	})
+
+	return results
+}
+
+// fetchBranches находит ветки репозитория, подходящие под шаблоны, и загружает коммиты тех из них,
+// что сдвинулись с прошлой проверки. Если коммит ветки загрузить не удалось, для неё остаётся
+// прежний SHA, и она проверяется снова в следующем цикле.
+func (m *Manager) fetchBranches(ctx context.Context, username string, repo models.Repository, opts fetchOptions, result *repoResult) error {
+	heads, err := m.githubClient.GetBranches(ctx, username, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(heads))
+	for branch := range heads {
+		if branch != repo.DefaultBranch && matchesAny(opts.branches, branch) {
+			names = append(names, branch)
+		}
+	}
+	sort.Strings(names)
+
+	known := opts.knownBranches[repo.Name]
+	result.branches = make(map[string]string, len(names))
+	for _, branch := range names {
+		sha := heads[branch]
+		result.branches[branch] = sha
+
+		last, seen := known[branch]
+		if !seen || last == sha {
+			continue
+		}
+		commits, err := m.githubClient.GetLatestCommit(ctx, username, repo.Name, branch)
+		if err != nil || len(commits) == 0 {
+			log.Printf("Failed to get commits of branch %s in %s: %v", branch, repo.Name, err)
+			result.branches[branch] = last
+			continue
+		}
+		commit := commits[0]
+		commit.Branch = branch
+		commit.NonDefaultBranch = true
+		result.branches[branch] = commit.SHA
+		result.branchCommits = append(result.branchCommits, branchCommit{commit: commit, previous: last})
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if filter.MatchBranch(pattern, branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// forEach вызывает job для индексов 0..n-1 не более чем в workers горутинах.
+// Для индексов, до которых не дошла очередь из-за отмены ctx, вызывается skip.
+func forEach(ctx context.Context, n, workers int, job, skip func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				job(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			for ; i < n; i++ {
+				skip(i)
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
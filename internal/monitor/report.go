@@ -0,0 +1,182 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/notify"
+	"github.com/DragonAirDragon/GO/internal/report"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+)
+
+const (
+	reportCheckInterval = time.Minute
+	reportTimeout       = 10 * time.Minute
+	reportRetryDelay    = 15 * time.Minute
+	// Если бот был выключен дольше, пропущенный отчёт уже неактуален.
+	reportGracePeriod = 24 * time.Hour
+)
+
+// runReports отправляет еженедельные отчёты по расписанию чатов.
+func (m *Manager) runReports(ctx context.Context) {
+	ticker := time.NewTicker(reportCheckInterval)
+	defer ticker.Stop()
+
+	runs := &reportRuns{inFlight: make(map[string]bool), retryAt: make(map[string]time.Time)}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.sendDueReports(ctx, now, runs)
+		}
+	}
+}
+
+// reportRuns - отчёты, которые собираются сейчас, и время повтора тех, что собрать не удалось.
+// Ключ - аккаунт и время расписания: несколько чатов могут ждать один и тот же отчёт.
+type reportRuns struct {
+	mu       sync.Mutex
+	inFlight map[string]bool
+	retryAt  map[string]time.Time
+}
+
+// start помечает отчёт как собираемый; false - он уже собирается или ждёт повтора.
+func (r *reportRuns) start(key string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inFlight[key] || now.Before(r.retryAt[key]) {
+		return false
+	}
+	r.inFlight[key] = true
+	return true
+}
+
+func (r *reportRuns) finish(key string, retryAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.inFlight, key)
+	if retryAt.IsZero() {
+		delete(r.retryAt, key)
+	} else {
+		r.retryAt[key] = retryAt
+	}
+}
+
+// sendDueReports находит чаты, которым пора отправить отчёт, и собирает отчёты в отдельных
+// горутинах: сбор занимает минуты и не должен задерживать проверку расписания.
+// LastSent обновляется только после отправки, поэтому несобранный отчёт повторяется через reportRetryDelay.
+func (m *Manager) sendDueReports(ctx context.Context, now time.Time, runs *reportRuns) {
+	type job struct {
+		username  string
+		scheduled time.Time
+		chats     []int64
+	}
+	jobs := make(map[string]*job)
+
+	for _, chatID := range m.telegramBot.ActiveChats() {
+		config, _ := m.telegramBot.GetConfig(chatID)
+		if config.Report.Off || config.Paused {
+			continue
+		}
+
+		scheduled := report.LastScheduled(config, now)
+		if !config.Report.LastSent.Before(scheduled) {
+			continue
+		}
+
+		// Первый запуск после подписки или долгий простой: ждём следующего расписания.
+		if config.Report.LastSent.IsZero() || now.Sub(scheduled) > reportGracePeriod {
+			m.telegramBot.UpdateConfig(chatID, func(c *models.MonitoringConfig) {
+				c.Report.LastSent = now
+			})
+			continue
+		}
+
+		key := config.GitHubUsername + "@" + scheduled.UTC().Format(time.RFC3339)
+		if jobs[key] == nil {
+			jobs[key] = &job{username: config.GitHubUsername, scheduled: scheduled}
+		}
+		jobs[key].chats = append(jobs[key].chats, chatID)
+	}
+
+	for key, j := range jobs {
+		if !runs.start(key, now) {
+			continue
+		}
+		go func() {
+			var retryAt time.Time
+			defer func() { runs.finish(key, retryAt) }()
+
+			collectCtx, cancel := context.WithTimeout(ctx, reportTimeout)
+			r, err := report.Collect(collectCtx, m.githubClient, j.username, j.scheduled)
+			cancel()
+			if err != nil {
+				log.Printf("Failed to collect weekly report for %s, retrying in %s: %v", j.username, reportRetryDelay, err)
+				retryAt = time.Now().Add(reportRetryDelay)
+				return
+			}
+
+			for _, chatID := range j.chats {
+				if err := m.deliverReport(ctx, chatID, r); err != nil {
+					log.Printf("Failed to deliver weekly report to chat %d, retrying in %s: %v", chatID, reportRetryDelay, err)
+					retryAt = time.Now().Add(reportRetryDelay)
+					continue
+				}
+				m.telegramBot.UpdateConfig(chatID, func(c *models.MonitoringConfig) {
+					c.Report.LastSent = now
+				})
+			}
+		}()
+	}
+}
+
+// sendReport собирает отчёт по запросу /report.
+func (m *Manager) sendReport(callback telegram.MonitoringCallback) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	r, err := report.Collect(ctx, m.githubClient, callback.Username, time.Now())
+	if err != nil {
+		log.Printf("Failed to collect report for %s: %v", callback.Username, err)
+		m.sendMessage(callback.ChatID, "❌ Не удалось собрать отчёт по аккаунту <b>"+html.EscapeString(callback.Username)+"</b>.")
+		return
+	}
+
+	if err := m.deliverReport(ctx, callback.ChatID, r); err != nil {
+		log.Printf("Failed to deliver report to chat %d: %v", callback.ChatID, err)
+	}
+}
+
+// deliverReport отправляет отчёт и ждёт, пока Telegram примет текст. Часть, на которой отправитель
+// исчерпал попытки, уже сохранена в недоставленных и ошибкой не считается. После ошибки отчёт
+// при повторе отправляется целиком. График - дополнение, и без него отчёт считается отправленным.
+func (m *Manager) deliverReport(ctx context.Context, chatID int64, r report.Report) error {
+	config, _ := m.telegramBot.GetConfig(chatID)
+	threadID := m.telegramBot.ThreadFor(chatID, r.Account, "")
+
+	for _, part := range notify.SplitMessage(report.Format(r, config)) {
+		msg := telegram.OutgoingMessage{ChatID: chatID, ThreadID: threadID, Text: part}
+		err := m.telegramBot.Deliver(ctx, msg)
+		var failure telegram.DeliveryFailure
+		if err != nil && !errors.As(err, &failure) {
+			return err
+		}
+	}
+
+	if r.Current.Commits == 0 {
+		return nil
+	}
+	png, err := weeklyChart(r, config.Location())
+	if err != nil {
+		log.Printf("Failed to build weekly chart for %s: %v", r.Account, err)
+		return nil
+	}
+	m.sendPhoto(chatID, r.Account, png, "📊 Коммиты по дням")
+	return nil
+}
@@ -0,0 +1,365 @@
+package monitor
+
+import (
+	"context"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/github"
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// schedulerTick - точность планировщика: интервалы задаются в минутах.
+const schedulerTick = 10 * time.Second
+
+type controlOp int
+
+const (
+	opSubscribe controlOp = iota
+	opUnsubscribe
+	opMigrate
+	opSetInterval
+	opPause
+	opResume
+	opCheckNow
+	opPollDone
+)
+
+// controlMessage - команда планировщику. Все изменения подписок проходят через канал control,
+// поэтому состояние планировщика не требует блокировок.
+type controlMessage struct {
+	op        controlOp
+	chatID    int64
+	newChatID int64
+	username  string
+	interval  int
+
+	// Для opPollDone.
+	state     *accountState
+	repoCount int
+	result    pollResult
+	err       error // ошибка первичной загрузки аккаунта
+}
+
+type subscriber struct {
+	interval int // минуты
+	paused   bool
+	welcomed bool // отправлено ли сообщение о запуске мониторинга
+}
+
+// accountState - общее состояние опроса одного аккаунта GitHub для всех подписанных чатов.
+type accountState struct {
+	name        string
+	subscribers map[int64]*subscriber
+	initialized bool
+	running     bool
+	checkAgain  bool    // проверку запросили во время опроса
+	force       bool    // следующий опрос проверяет все репозитории (/check)
+	waiting     []int64 // чаты, ждущие результата следующего опроса (/check)
+	checking    []int64 // чаты, ждущие результата текущего опроса
+	quietCycles int     // циклов подряд без событий и без свежих пушей
+	repoCount   int
+	lastRun     time.Time
+	nextRun     time.Time
+
+	// known и repoPolls меняет только горутина опроса, пока running == true.
+	known     models.AccountSnapshot
+	repoPolls map[string]*repoPoll
+}
+
+func newAccountState(name string) *accountState {
+	return &accountState{
+		name:        name,
+		subscribers: make(map[int64]*subscriber),
+		known:       models.NewAccountSnapshot(),
+		repoPolls:   make(map[string]*repoPoll),
+	}
+}
+
+// interval - самый короткий интервал среди активных подписчиков.
+func (s *accountState) interval() time.Duration {
+	shortest := 0
+	for _, sub := range s.subscribers {
+		if !sub.paused && (shortest == 0 || sub.interval < shortest) {
+			shortest = sub.interval
+		}
+	}
+	if shortest < 1 {
+		shortest = 1
+	}
+	return time.Duration(shortest) * time.Minute
+}
+
+// activeChats возвращает чаты, которым доставляются события аккаунта.
+func (s *accountState) activeChats() []int64 {
+	var chats []int64
+	for chatID, sub := range s.subscribers {
+		if !sub.paused {
+			chats = append(chats, chatID)
+		}
+	}
+	return chats
+}
+
+func (s *accountState) reschedule() {
+	if s.initialized {
+		s.nextRun = s.lastRun.Add(s.pollInterval())
+	}
+}
+
+// checkNow запускает опрос при ближайшем проходе планировщика, не пересекаясь с текущим опросом.
+func (s *accountState) checkNow() {
+	if s.running {
+		s.checkAgain = true
+		return
+	}
+	s.nextRun = time.Time{}
+}
+
+// runScheduler запускает опросы аккаунтов по расписанию и применяет команды из канала control.
+func (m *Manager) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case msg := <-m.control:
+			m.handleControl(msg)
+		}
+		m.runDue(ctx, time.Now())
+	}
+}
+
+func (m *Manager) handleControl(msg controlMessage) {
+	switch msg.op {
+	case opSubscribe:
+		m.subscribe(msg.chatID, msg.username, msg.interval)
+
+	case opUnsubscribe:
+		m.unsubscribe(msg.chatID)
+		log.Printf("Monitoring stopped for chat %d", msg.chatID)
+
+	case opMigrate:
+		m.migrate(msg.chatID, msg.newChatID)
+		log.Printf("Monitoring moved from chat %d to chat %d", msg.chatID, msg.newChatID)
+
+	case opPollDone:
+		m.pollDone(msg)
+
+	default:
+		state, sub := m.subscription(msg.chatID)
+		if sub == nil {
+			return
+		}
+
+		switch msg.op {
+		case opSetInterval:
+			sub.interval = msg.interval
+			// Более короткий интервал начинает действовать сразу, а не после старого.
+			if next := state.lastRun.Add(state.interval()); state.initialized && next.Before(state.nextRun) {
+				state.nextRun = next
+			}
+			log.Printf("Updated interval to %d minutes for chat %d", msg.interval, msg.chatID)
+
+		case opPause:
+			sub.paused = true
+			state.reschedule()
+			log.Printf("Monitoring paused for chat %d", msg.chatID)
+
+		case opResume:
+			sub.paused = false
+			state.quietCycles = 0
+			state.checkNow()
+			log.Printf("Monitoring resumed for chat %d", msg.chatID)
+
+		case opCheckNow:
+			switch {
+			case !state.initialized:
+				m.sendMessage(msg.chatID, "Мониторинг ещё запускается, первая проверка скоро завершится.")
+			case sub.paused:
+				m.sendMessage(msg.chatID, "Мониторинг приостановлен. Возобновить: /resume")
+			default:
+				// Результат получит следующий опрос: текущий мог начаться раньше пуша.
+				state.waiting = append(state.waiting, msg.chatID)
+				state.force = true
+				state.checkNow()
+			}
+		}
+	}
+}
+
+func (m *Manager) subscription(chatID int64) (*accountState, *subscriber) {
+	key, exists := m.chats[chatID]
+	if !exists {
+		return nil, nil
+	}
+	state := m.accounts[key]
+	return state, state.subscribers[chatID]
+}
+
+// subscribe подписывает чат на аккаунт. Если аккаунт уже опрашивается для других чатов,
+// новый чат сразу получает общее состояние и не вызывает повторной загрузки репозиториев.
+func (m *Manager) subscribe(chatID int64, username string, interval int) {
+	key := strings.ToLower(username)
+	m.unsubscribe(chatID)
+
+	state, exists := m.accounts[key]
+	if !exists {
+		state = newAccountState(username)
+		m.accounts[key] = state
+	}
+	sub := &subscriber{interval: interval}
+	state.subscribers[chatID] = sub
+	m.chats[chatID] = key
+
+	if state.initialized {
+		sub.welcomed = true
+		if next := state.lastRun.Add(state.interval()); next.Before(state.nextRun) {
+			state.nextRun = next
+		}
+		m.sendStarted(chatID, state.name, state.repoCount, interval)
+	}
+}
+
+// unsubscribe отписывает чат; аккаунт без подписчиков перестаёт опрашиваться.
+func (m *Manager) unsubscribe(chatID int64) {
+	key, exists := m.chats[chatID]
+	if !exists {
+		return
+	}
+	delete(m.chats, chatID)
+
+	state := m.accounts[key]
+	delete(state.subscribers, chatID)
+	if len(state.subscribers) == 0 {
+		delete(m.accounts, key)
+		log.Printf("No subscribers left for GitHub account %s, polling stopped", state.name)
+		return
+	}
+	state.reschedule()
+}
+
+func (m *Manager) migrate(oldChatID, newChatID int64) {
+	key, exists := m.chats[oldChatID]
+	if !exists {
+		return
+	}
+	delete(m.chats, oldChatID)
+	m.chats[newChatID] = key
+
+	state := m.accounts[key]
+	state.subscribers[newChatID] = state.subscribers[oldChatID]
+	delete(state.subscribers, oldChatID)
+	replaceChatID(state.waiting, oldChatID, newChatID)
+	replaceChatID(state.checking, oldChatID, newChatID)
+}
+
+func replaceChatID(chats []int64, oldChatID, newChatID int64) {
+	for i, chatID := range chats {
+		if chatID == oldChatID {
+			chats[i] = newChatID
+		}
+	}
+}
+
+func (m *Manager) runDue(ctx context.Context, now time.Time) {
+	for _, state := range m.accounts {
+		if state.running || len(state.activeChats()) == 0 || now.Before(state.nextRun) {
+			continue
+		}
+		state.running = true
+		state.checking, state.waiting = state.waiting, nil
+		force := state.force
+		state.force = false
+		go m.pollAccount(ctx, state, state.initialized, state.interval(), force)
+	}
+}
+
+// pollAccount выполняет один цикл опроса в отдельной горутине и сообщает планировщику о завершении.
+func (m *Manager) pollAccount(ctx context.Context, state *accountState, initialized bool, deadline time.Duration, force bool) {
+	done := controlMessage{op: opPollDone, state: state}
+
+	if initialized {
+		done.result = m.poll(ctx, state, deadline, force)
+	} else {
+		restored, err := m.initAccount(ctx, state, deadline)
+		done.err = err
+		// После перезапуска сразу доставляются события, пропущенные за время простоя.
+		if restored {
+			done.result = m.poll(ctx, state, deadline, force)
+		}
+	}
+	done.repoCount = len(state.known.Repos)
+
+	select {
+	case m.control <- done:
+	case <-ctx.Done():
+	}
+}
+
+func (m *Manager) pollDone(msg controlMessage) {
+	state := msg.state
+	state.running = false
+	state.lastRun = time.Now()
+
+	// Пока шёл опрос, от аккаунта могли отписаться все чаты.
+	if m.accounts[strings.ToLower(state.name)] != state {
+		return
+	}
+
+	if msg.err != nil {
+		if !github.IsNotFound(msg.err) {
+			// Сбой сети или лимит запросов: аккаунт загрузится при следующей попытке.
+			state.nextRun = state.lastRun.Add(jitter(state.interval()))
+			log.Printf("Failed to start monitoring %s, retrying at %s: %v", state.name, state.nextRun.Format(time.TimeOnly), msg.err)
+			return
+		}
+
+		// Аккаунта нет: отписываем все чаты и выключаем их подписку, иначе после перезапуска
+		// опрос начнётся снова.
+		for chatID := range state.subscribers {
+			m.unsubscribe(chatID)
+			m.telegramBot.UpdateConfig(chatID, func(c *models.MonitoringConfig) {
+				if strings.EqualFold(c.GitHubUsername, state.name) {
+					c.IsActive = false
+				}
+			})
+			m.sendMessage(chatID, "❌ Пользователь GitHub <b>"+html.EscapeString(state.name)+"</b> не найден, мониторинг остановлен. "+
+				"Проверьте имя и начните заново: /track &lt;username&gt;")
+		}
+		return
+	}
+
+	for _, chatID := range state.checking {
+		if _, subscribed := state.subscribers[chatID]; subscribed {
+			m.sendCheckResult(chatID, state.name, msg.result)
+		}
+	}
+	state.checking = nil
+
+	if msg.result.active || !state.initialized {
+		state.quietCycles = 0
+	} else {
+		state.quietCycles++
+	}
+
+	state.initialized = true
+	state.repoCount = msg.repoCount
+	state.nextRun = state.lastRun.Add(state.pollInterval())
+	if state.checkAgain {
+		state.checkAgain = false
+		state.nextRun = time.Time{}
+	}
+
+	for chatID, sub := range state.subscribers {
+		if !sub.welcomed {
+			sub.welcomed = true
+			m.sendStarted(chatID, state.name, state.repoCount, sub.interval)
+		}
+	}
+}
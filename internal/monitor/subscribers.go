@@ -0,0 +1,363 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/DragonAirDragon/GO/internal/deps"
+	"github.com/DragonAirDragon/GO/internal/filter"
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/notify"
+	"github.com/DragonAirDragon/GO/internal/secrets"
+)
+
+// subscribeHandlers подписывает на шину каналы доставки и проверку секретов: каждый обрабатывает
+// событие независимо, общие у них только фильтры чата (notifications). Хранение не подписано на шину: события попадают в outbox одной транзакцией с состоянием
+// аккаунта ещё при опросе (см. poll), а шина получает их уже из outbox.
+func (m *Manager) subscribeHandlers() {
+	m.bus.SubscribeAll(m.deliverToChats)
+	m.bus.SubscribeAll(m.deliverToEmails)
+	m.bus.SubscribeAll(m.deliverToSinks)
+	m.bus.Subscribe(models.EventCommit, m.scanPush)
+}
+
+// channel - канал доставки уведомлений чата; key отличает его в журнале доставки outbox.
+type channel struct {
+	key      string
+	notifier notify.Notifier
+}
+
+// deliverToChats отправляет уведомления в чаты Telegram с учётом сводок и тихих часов.
+func (m *Manager) deliverToChats(ctx context.Context, event models.Event) error {
+	return m.fanOut(ctx, event, func(chatID int64, _ models.MonitoringConfig) []channel {
+		return []channel{{key: fmt.Sprintf("chat:%d", chatID), notifier: m.dispatcher.ForChat(chatID)}}
+	})
+}
+
+// deliverToEmails копит уведомления для почтовых сводок из /email.
+func (m *Manager) deliverToEmails(ctx context.Context, event models.Event) error {
+	if !m.dispatcher.EmailEnabled() {
+		return nil
+	}
+	return m.fanOut(ctx, event, func(chatID int64, config models.MonitoringConfig) []channel {
+		channels := make([]channel, 0, len(config.Emails))
+		for _, recipient := range config.Emails {
+			if !recipient.Confirmed {
+				continue
+			}
+			channels = append(channels, channel{
+				key:      fmt.Sprintf("email:%d:%s", chatID, strings.ToLower(recipient.Address)),
+				notifier: m.dispatcher.ForEmail(chatID, recipient),
+			})
+		}
+		return channels
+	})
+}
+
+// deliverToSinks ставит уведомления в очередь внешних получателей из /sink.
+// Адрес webhook - секрет, поэтому в журнал доставки попадает только его хэш.
+func (m *Manager) deliverToSinks(ctx context.Context, event models.Event) error {
+	return m.fanOut(ctx, event, func(chatID int64, config models.MonitoringConfig) []channel {
+		channels := make([]channel, 0, len(config.Sinks))
+		for _, sink := range config.Sinks {
+			if err := sink.Validate(); err != nil {
+				log.Printf("Skipping sink of chat %d: %v", chatID, err)
+				continue
+			}
+			channels = append(channels, channel{
+				key:      fmt.Sprintf("sink:%d:%s", chatID, sink.ID()),
+				notifier: m.dispatcher.ForSink(chatID, sink),
+			})
+		}
+		return channels
+	})
+}
+
+// fanOut применяет к событию настройки каждого подписанного чата (/settings, /filter, /alert)
+// и передаёт получившиеся уведомления в каналы, которые вернул channels.
+// Ошибки каналов возвращаются вместе: outbox повторит событие только для тех, кому оно не дошло.
+func (m *Manager) fanOut(ctx context.Context, event models.Event, channels func(chatID int64, config models.MonitoringConfig) []channel) error {
+	n := notify.FromEvent(event)
+
+	var chats []int64
+	for _, chatID := range m.recipients(n.Account) {
+		config, _ := m.telegramBot.GetConfig(chatID)
+		if len(channels(chatID, config)) > 0 {
+			chats = append(chats, chatID)
+		}
+	}
+	if len(chats) == 0 {
+		return nil
+	}
+
+	// Файлы коммита нужны фильтрам по путям и уведомлению о зависимостях; загружаются один раз
+	// на всех подписчиков шины.
+	if n.Commit != nil {
+		if err := m.loadFiles(ctx, &n); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, chatID := range chats {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		config, _ := m.telegramBot.GetConfig(chatID)
+		targets := channels(chatID, config)
+		for _, item := range notifications(config, n) {
+			for _, target := range targets {
+				err := deliverOnce(ctx, target.key+":"+string(item.Type), func() error {
+					return target.notifier.Notify(ctx, item)
+				})
+				if err != nil {
+					log.Printf("Failed to deliver %s event of %s/%s for chat %d: %v", item.Type, item.Account, item.Repo, chatID, err)
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifications возвращает уведомления, которые чат получит о событии; файлы коммита уже загружены (см. fanOut).
+// Коммит, изменивший манифесты, дополнительно приходит уведомлением о зависимостях; срочным помечается
+// только первое из них, чтобы правило /alert не срабатывало дважды.
+// Ветки, кроме ветки по умолчанию, опрашиваются по правилам всех подписчиков аккаунта, поэтому коммит
+// из такой ветки проходит только через собственное правило чата /filter include branch.
+func notifications(config models.MonitoringConfig, n notify.Notification) []notify.Notification {
+	if n.Commit != nil && (len(config.Filters) > 0 || n.Commit.NonDefaultBranch) {
+		if !filter.Match(config.Filters, *n.Commit) {
+			return nil
+		}
+	}
+
+	var result []notify.Notification
+	if config.Wants(n.Type) {
+		result = append(result, n)
+	}
+	if n.Type == models.EventCommit && config.Wants(models.EventDependency) {
+		if manifests := deps.Diff(n.Commit.Files); len(manifests) > 0 {
+			dependency := n
+			dependency.Type = models.EventDependency
+			dependency.Manifests = manifests
+			result = append(result, dependency)
+		}
+	}
+
+	if len(result) > 0 {
+		if rule, ok := filter.MatchAlert(config.AlertRules, alertText(n)); ok {
+			result[0].Alert = rule.Pattern
+		}
+	}
+	return result
+}
+
+// alertText возвращает текст события, по которому проверяются правила /alert.
+func alertText(n notify.Notification) string {
+	switch {
+	case n.Commit != nil:
+		return n.Commit.Message
+	case n.Repository != nil:
+		return n.Repository.Name + "\n" + n.Repository.Description
+	case n.PullRequest != nil:
+		return n.PullRequest.Title
+	}
+	return ""
+}
+
+// Сколько коммитов одного пуша проверяется на секреты: на каждый нужен отдельный запрос к API.
+const maxScannedCommits = 50
+
+// scanPush ищет утёкшие ключи и токены во всех коммитах пуша.
+// Проверка не зависит от /settings и /filter: о ключе в истории нужно знать в любом случае.
+func (m *Manager) scanPush(ctx context.Context, event models.Event) error {
+	e, ok := event.(models.CommitPushed)
+	if !ok {
+		return nil
+	}
+	n := notify.FromEvent(event)
+
+	var chats []int64
+	for _, chatID := range m.recipients(n.Account) {
+		if config, _ := m.telegramBot.GetConfig(chatID); !config.SecretScanOff {
+			chats = append(chats, chatID)
+		}
+	}
+	if len(chats) == 0 {
+		return nil
+	}
+
+	if err := m.loadFiles(ctx, &n); err != nil {
+		return err
+	}
+	pushed := m.pushedCommits(ctx, n, e.Previous)
+	var errs []error
+	for _, chatID := range chats {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := m.scanSecrets(ctx, chatID, n, pushed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pushedCommits возвращает все коммиты пуша - от previous до последнего - со списками файлов:
+// ключ мог попасть в промежуточный коммит и быть удалён следующим. Если прошлый коммит неизвестен
+// или сравнение не удалось, проверяется только последний коммит.
+func (m *Manager) pushedCommits(ctx context.Context, n notify.Notification, previous string) []*models.Commit {
+	head := []*models.Commit{n.Commit}
+	if previous == "" {
+		return head
+	}
+
+	commits, err := m.githubClient.CompareCommits(ctx, n.Account, n.Repo, previous, n.Commit.SHA)
+	if err != nil {
+		log.Printf("Failed to compare %s...%s in %s, scanning only the latest commit: %v", previous, n.Commit.SHA, n.Repo, err)
+		return head
+	}
+	if len(commits) > maxScannedCommits {
+		log.Printf("Push to %s/%s has %d commits, scanning the latest %d", n.Account, n.Repo, len(commits), maxScannedCommits)
+		commits = commits[len(commits)-maxScannedCommits:]
+	}
+
+	pushed := make([]*models.Commit, 0, len(commits))
+	for _, commit := range commits {
+		if commit.SHA == n.Commit.SHA {
+			continue
+		}
+		detailed, err := m.getCommit(ctx, n.Account, n.Repo, commit.SHA)
+		if err != nil {
+			log.Printf("Failed to get files of commit %s in %s: %v", commit.SHA, n.Repo, err)
+			continue
+		}
+		detailed.Branch = n.Commit.Branch
+		pushed = append(pushed, &detailed)
+	}
+	return append(pushed, n.Commit)
+}
+
+// scanSecrets проверяет коммиты пуша по настройкам чата и сообщает отдельно о каждом коммите с находками.
+func (m *Manager) scanSecrets(ctx context.Context, chatID int64, n notify.Notification, pushed []*models.Commit) error {
+	config, _ := m.telegramBot.GetConfig(chatID)
+	if config.SecretScanOff {
+		return nil
+	}
+
+	var errs []error
+	for _, commit := range pushed {
+		var findings []models.SecretFinding
+		for _, finding := range secrets.Scan(commit.Files, secrets.Rules) {
+			if !secrets.Suppressed(config.SecretIgnores, n.Repo, finding) {
+				findings = append(findings, finding)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		log.Printf("Found %d possible secrets in commit %s of %s/%s", len(findings), commit.SHA, n.Account, n.Repo)
+		commitNotification := n
+		commitNotification.Commit = commit
+		err := deliverOnce(ctx, fmt.Sprintf("secrets:%d:%s", chatID, commit.SHA), func() error {
+			return m.dispatcher.DispatchSecrets(ctx, chatID, commitNotification, findings)
+		})
+		if err != nil {
+			log.Printf("Failed to report secrets in commit %s for chat %d: %v", commit.SHA, chatID, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadFiles догружает список файлов и диффы коммита, если их ещё нет.
+func (m *Manager) loadFiles(ctx context.Context, n *notify.Notification) error {
+	if n.Commit.Files != nil {
+		return nil
+	}
+
+	detailed, err := m.getCommit(ctx, n.Account, n.Repo, n.Commit.SHA)
+	if err != nil {
+		log.Printf("Failed to get files of commit %s in %s: %v", n.Commit.SHA, n.Repo, err)
+		return err
+	}
+	detailed.Branch = n.Commit.Branch
+	detailed.NonDefaultBranch = n.Commit.NonDefaultBranch
+	n.Commit = &detailed
+	return nil
+}
+
+// getCommit загружает коммит со списком файлов через кэш: одно событие разбирают несколько
+// подписчиков шины, и без кэша каждый загружал бы тот же дифф заново.
+// Возвращается копия, которую вызывающий может менять.
+func (m *Manager) getCommit(ctx context.Context, account, repo, sha string) (models.Commit, error) {
+	key := account + "/" + repo + "@" + sha
+	if commit, ok := m.commits.get(key); ok {
+		return commit, nil
+	}
+
+	detailed, err := m.githubClient.GetCommit(ctx, account, repo, sha)
+	if err != nil {
+		return models.Commit{}, err
+	}
+	if detailed.Files == nil {
+		detailed.Files = []models.CommitFile{}
+	}
+	m.loadManifests(ctx, account, repo, detailed)
+	m.commits.put(key, *detailed)
+	return *detailed, nil
+}
+
+// loadManifests загружает содержимое манифестов, которые deps разбирает целиком, до и после коммита.
+// Если файл загрузить не удалось, изменения его зависимостей не попадут в уведомление, остальное
+// событие при этом доставляется.
+func (m *Manager) loadManifests(ctx context.Context, account, repo string, commit *models.Commit) {
+	for i := range commit.Files {
+		file := &commit.Files[i]
+		if !deps.NeedsContent(file.Filename) {
+			continue
+		}
+
+		var err error
+		if file.Status != "removed" {
+			file.Content, err = m.githubClient.GetFileContent(ctx, account, repo, file.Filename, commit.SHA)
+		}
+		if err == nil && file.Status != "added" && commit.Parent != "" {
+			file.PreviousContent, err = m.githubClient.GetFileContent(ctx, account, repo, file.Filename, commit.Parent)
+		}
+		if err != nil {
+			log.Printf("Failed to get %s of commit %s in %s: %v", file.Filename, commit.SHA, repo, err)
+			file.Content, file.PreviousContent = "", ""
+		}
+	}
+}
+
+// Кэш ограничен: коммиты нужны только пока шина разбирает событие.
+const maxCachedCommits = 256
+
+type commitCache struct {
+	mu      sync.Mutex
+	commits map[string]models.Commit
+}
+
+func (c *commitCache) get(key string) (models.Commit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	commit, ok := c.commits[key]
+	return commit, ok
+}
+
+func (c *commitCache) put(key string, commit models.Commit) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.commits == nil || len(c.commits) >= maxCachedCommits {
+		c.commits = make(map[string]models.Commit)
+	}
+	c.commits[key] = commit
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/notify"
+)
+
+func TestNotificationsBranchRules(t *testing.T) {
+	// Ветка release/* опрашивается из-за правила первого чата; второй чат правил не задавал.
+	withRule := models.MonitoringConfig{Filters: []models.FilterRule{
+		{Mode: models.FilterInclude, Field: models.FilterBranch, Pattern: "release/*"},
+	}}
+	withoutRules := models.MonitoringConfig{}
+
+	commit := func(branch string, nonDefault bool) notify.Notification {
+		return notify.Notification{
+			Type:    models.EventCommit,
+			Account: "octocat",
+			Repo:    "hello",
+			Commit: &models.Commit{
+				SHA:              "abc1234",
+				Message:          "fix: bug",
+				Branch:           branch,
+				NonDefaultBranch: nonDefault,
+				Files:            []models.CommitFile{},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config models.MonitoringConfig
+		n      notify.Notification
+		want   int
+	}{
+		{"included branch, chat with rule", withRule, commit("release/1.0", true), 1},
+		{"included branch, chat without rules", withoutRules, commit("release/1.0", true), 0},
+		{"default branch, chat with rule", withRule, commit("main", false), 0},
+		{"default branch, chat without rules", withoutRules, commit("main", false), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notifications(tt.config, tt.n); len(got) != tt.want {
+				t.Errorf("notifications() returned %d items, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
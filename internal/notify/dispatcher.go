@@ -0,0 +1,532 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/storage"
+	"github.com/DragonAirDragon/GO/internal/telegram"
+)
+
+const (
+	digestCheckInterval = time.Minute
+	maxMessageLength    = 4000 // лимит Telegram - 4096 символов
+)
+
+var eventTitles = map[models.EventType]string{
+	models.EventNewRepo:     "🆕 Новые репозитории",
+	models.EventCommit:      "📝 Коммиты",
+	models.EventRelease:     "🏷 Релизы",
+	models.EventPullRequest: "🔀 Pull requests",
+	models.EventCI:          "⚙️ CI",
+	models.EventDependency:  "📦 Зависимости",
+}
+
+var eventOrder = models.EventTypes
+
+type pendingDigest struct {
+	ids   []int64 // записи в хранилище отложенных уведомлений
+	items []Notification
+	due   time.Time
+}
+
+func (p *pendingDigest) add(id int64, n Notification) {
+	p.ids = append(p.ids, id)
+	p.items = append(p.items, n)
+}
+
+type heldMessage struct {
+	ID       int64  `json:"-"` // запись в хранилище отложенных уведомлений
+	ThreadID int    `json:"thread_id,omitempty"`
+	Text     string `json:"text"`
+	Urgent   bool   `json:"urgent,omitempty"` // ждёт только конца snooze, тихие часы его не держат
+}
+
+// targetKey - сводка одного получателя (адреса почты или webhook) в рамках подписки чата.
+type targetKey struct {
+	chatID int64
+	target string
+}
+
+// Dispatcher решает, как доставить уведомление: сразу, в составе сводки
+// или после окончания тихих часов. Накопленные сводки и придержанные сообщения
+// хранятся в store и после перезапуска отправляются по прежнему расписанию;
+// то, что отправить так и не удалось, попадает туда же в очередь недоставленных.
+type Dispatcher struct {
+	telegramBot *telegram.Bot
+	mailer      *Mailer // nil, если SMTP не настроен
+	store       Store
+	sinkClient  *http.Client
+
+	mu          sync.Mutex
+	ctx         context.Context // контекст Run: фоновые отправки прерываются вместе с ним
+	digests     map[int64]*pendingDigest
+	held        map[int64][]heldMessage
+	emails      map[targetKey]*pendingDigest
+	sinkDigests map[targetKey]*pendingDigest
+	sinkJobs    []*sinkJob
+	emailJobs   []*emailJob
+	migrated    map[int64]int64 // старый ID группы -> ID супергруппы
+}
+
+// Store - хранилище Dispatcher: отложенные уведомления и недоставленные сообщения.
+type Store interface {
+	storage.PendingStore
+	storage.DeadLetterStore
+}
+
+func NewDispatcher(telegramBot *telegram.Bot, mailer *Mailer, store Store) *Dispatcher {
+	return &Dispatcher{
+		telegramBot: telegramBot,
+		mailer:      mailer,
+		store:       store,
+		sinkClient:  NewSinkClient(sinkTimeout),
+		ctx:         context.Background(),
+		digests:     make(map[int64]*pendingDigest),
+		held:        make(map[int64][]heldMessage),
+		emails:      make(map[targetKey]*pendingDigest),
+		sinkDigests: make(map[targetKey]*pendingDigest),
+		migrated:    make(map[int64]int64),
+	}
+}
+
+// Dispatch доставляет уведомление в чат. nil означает, что уведомление передано дальше
+// окончательно: Telegram принял сообщение или оно сохранено до сводки или конца тихих часов.
+// При ошибке уведомление никуда не поставлено, и его нужно повторить.
+func (d *Dispatcher) Dispatch(ctx context.Context, chatID int64, n Notification) error {
+	config, _ := d.telegramBot.GetConfig(chatID)
+
+	if n.Alert != "" {
+		return d.alert(ctx, chatID, config, n)
+	}
+
+	if config.Digest.Mode == models.DigestOff || config.Digest.Excluded[n.Type] {
+		return d.deliver(ctx, chatID, config, d.telegramBot.ThreadFor(chatID, n.Account, n.Repo), formatText(n, config))
+	}
+
+	n = compact(n)
+	due := nextDigestTime(time.Now(), config)
+	id, err := d.persist(storage.PendingNotification{Kind: storage.PendingDigest, ChatID: chatID, Due: due}, n)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	digest, exists := d.digests[chatID]
+	if !exists {
+		digest = &pendingDigest{due: due}
+		d.digests[chatID] = digest
+	}
+	digest.add(id, n)
+	return nil
+}
+
+// DispatchSecrets срочно сообщает о секретах, найденных в коммите.
+func (d *Dispatcher) DispatchSecrets(ctx context.Context, chatID int64, n Notification, findings []models.SecretFinding) error {
+	config, _ := d.telegramBot.GetConfig(chatID)
+	return d.urgent(ctx, chatID, config, n, func(sourceChatID int64) string {
+		return formatSecretAlert(n, findings, sourceChatID)
+	})
+}
+
+func (d *Dispatcher) alert(ctx context.Context, chatID int64, config models.MonitoringConfig, n Notification) error {
+	return d.urgent(ctx, chatID, config, n, func(sourceChatID int64) string {
+		return formatAlert(n, config, sourceChatID)
+	})
+}
+
+// urgent отправляет сообщение сразу и со звуком, минуя сводки и тихие часы,
+// и дублирует его в чат эскалации, если он задан. Snooze - явная просьба не беспокоить,
+// поэтому на его время придерживаются и срочные сообщения.
+func (d *Dispatcher) urgent(ctx context.Context, chatID int64, config models.MonitoringConfig, n Notification, format func(sourceChatID int64) string) error {
+	if err := d.sendUrgent(ctx, chatID, config, d.telegramBot.ThreadFor(chatID, n.Account, n.Repo), format(0)); err != nil {
+		return err
+	}
+
+	if config.EscalationChatID != 0 && config.EscalationChatID != chatID {
+		escalation, _ := d.telegramBot.GetConfig(config.EscalationChatID)
+		return d.sendUrgent(ctx, config.EscalationChatID, escalation, 0, format(chatID))
+	}
+	return nil
+}
+
+func (d *Dispatcher) sendUrgent(ctx context.Context, chatID int64, config models.MonitoringConfig, threadID int, text string) error {
+	if time.Now().Before(config.SnoozeUntil) {
+		return d.hold(chatID, heldMessage{ThreadID: threadID, Text: text, Urgent: true})
+	}
+	return d.deliverNow(ctx, chatID, threadID, text, false)
+}
+
+// deliver учитывает тихие часы и snooze чата.
+func (d *Dispatcher) deliver(ctx context.Context, chatID int64, config models.MonitoringConfig, threadID int, text string) error {
+	switch config.QuietMode(time.Now()) {
+	case models.QuietHold:
+		return d.hold(chatID, heldMessage{ThreadID: threadID, Text: text})
+	case models.QuietSilent:
+		return d.deliverNow(ctx, chatID, threadID, text, true)
+	default:
+		return d.deliverNow(ctx, chatID, threadID, text, false)
+	}
+}
+
+func (d *Dispatcher) hold(chatID int64, msg heldMessage) error {
+	id, err := d.persist(storage.PendingNotification{Kind: storage.PendingHeld, ChatID: chatID, Due: time.Now()}, msg)
+	if err != nil {
+		return err
+	}
+	msg.ID = id
+
+	d.mu.Lock()
+	d.held[chatID] = append(d.held[chatID], msg)
+	d.mu.Unlock()
+	return nil
+}
+
+// Run отправляет накопленные сводки по расписанию, пока не отменён контекст.
+// Сначала возвращает в очередь то, что было отложено до перезапуска.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.mu.Lock()
+	d.ctx = ctx
+	d.mu.Unlock()
+
+	d.restore(ctx)
+
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.flushDue(ctx, now)
+		}
+	}
+}
+
+// flushDue отправляет то, время чего пришло. Записи удаляются из хранилища только после того,
+// как Telegram принял сообщения; то, что отправить не удалось, ждёт следующей проверки.
+func (d *Dispatcher) flushDue(ctx context.Context, now time.Time) {
+	due := make(map[int64]*pendingDigest)
+	released := make(map[int64][]heldMessage)
+	emails := make(map[targetKey]*pendingDigest)
+
+	d.mu.Lock()
+	for chatID, messages := range d.held {
+		config, _ := d.telegramBot.GetConfig(chatID)
+		quiet := config.QuietMode(now) == models.QuietHold
+		snoozed := now.Before(config.SnoozeUntil)
+
+		var kept []heldMessage
+		for _, msg := range messages {
+			if (msg.Urgent && snoozed) || (!msg.Urgent && quiet) {
+				kept = append(kept, msg)
+				continue
+			}
+			released[chatID] = append(released[chatID], msg)
+		}
+		if len(kept) == 0 {
+			delete(d.held, chatID)
+		} else {
+			d.held[chatID] = kept
+		}
+	}
+
+	for chatID, digest := range d.digests {
+		config, _ := d.telegramBot.GetConfig(chatID)
+		// Если сводки выключили, накопленное отправляется сразу.
+		if config.Digest.Mode != models.DigestOff && now.Before(digest.due) {
+			continue
+		}
+		due[chatID] = digest
+		delete(d.digests, chatID)
+	}
+
+	for key, digest := range d.emails {
+		if now.Before(digest.due) {
+			continue
+		}
+		emails[key] = digest
+		delete(d.emails, key)
+	}
+
+	sinkDigests := make(map[targetKey]*pendingDigest)
+	for key, digest := range d.sinkDigests {
+		config, _ := d.telegramBot.GetConfig(key.chatID)
+		if config.Digest.Mode != models.DigestOff && now.Before(digest.due) {
+			continue
+		}
+		sinkDigests[key] = digest
+		delete(d.sinkDigests, key)
+	}
+	d.mu.Unlock()
+
+	for key, digest := range sinkDigests {
+		if err := d.queueSinkDigest(key, digest); err != nil {
+			log.Printf("Failed to queue sink digest for chat %d, retrying later: %v", key.chatID, err)
+			d.mu.Lock()
+			requeue(d.sinkDigests, targetKey{chatID: d.currentChat(key.chatID), target: key.target}, digest)
+			d.mu.Unlock()
+		}
+	}
+	d.startSinks(now)
+
+	for chatID, messages := range released {
+		log.Printf("Quiet period is over for chat %d, releasing %d held messages", chatID, len(messages))
+		ids := make([]int64, 0, len(messages))
+		for i, msg := range messages {
+			if err := d.deliverNow(ctx, chatID, msg.ThreadID, msg.Text, false); err != nil {
+				// Неотправленные сообщения ждут следующей проверки.
+				log.Printf("Failed to release held messages for chat %d: %v", chatID, err)
+				d.mu.Lock()
+				current := d.currentChat(chatID)
+				d.held[current] = append(messages[i:len(messages):len(messages)], d.held[current]...)
+				d.mu.Unlock()
+				break
+			}
+			ids = append(ids, msg.ID)
+		}
+		d.forget(ids)
+	}
+
+	for chatID, digest := range due {
+		sent, unsent, err := d.flush(ctx, chatID, digest)
+		d.forget(sent)
+		if err != nil {
+			// Уже отправленные группы повторно не отправляются.
+			log.Printf("Failed to send digest to chat %d, retrying %d events later: %v", chatID, len(unsent.items), err)
+			d.mu.Lock()
+			requeue(d.digests, d.currentChat(chatID), unsent)
+			d.mu.Unlock()
+		}
+	}
+
+	for key, digest := range emails {
+		if err := d.queueEmail(key, digest); err != nil {
+			log.Printf("Failed to queue email digest for chat %d, retrying later: %v", key.chatID, err)
+			d.mu.Lock()
+			requeue(d.emails, targetKey{chatID: d.currentChat(key.chatID), target: key.target}, digest)
+			d.mu.Unlock()
+		}
+	}
+	d.startEmails(now)
+}
+
+// requeue возвращает сводку, которую не удалось отправить, до следующей проверки.
+// Должен вызываться под d.mu.
+func requeue[K comparable](digests map[K]*pendingDigest, key K, digest *pendingDigest) {
+	if pending, exists := digests[key]; exists {
+		digest.ids = append(digest.ids, pending.ids...)
+		digest.items = append(digest.items, pending.items...)
+	}
+	digests[key] = digest
+}
+
+// Migrate переносит накопленные сводки, придержанные сообщения и отправки группы на ID супергруппы,
+// в которую её преобразовали; иначе всё это ушло бы в несуществующий чат.
+func (d *Dispatcher) Migrate(ctx context.Context, oldChatID, newChatID int64) {
+	d.mu.Lock()
+	d.migrated[oldChatID] = newChatID
+	if digest, exists := d.digests[oldChatID]; exists {
+		delete(d.digests, oldChatID)
+		requeue(d.digests, newChatID, digest)
+	}
+	if messages, exists := d.held[oldChatID]; exists {
+		delete(d.held, oldChatID)
+		d.held[newChatID] = append(messages, d.held[newChatID]...)
+	}
+	for _, digests := range []map[targetKey]*pendingDigest{d.emails, d.sinkDigests} {
+		for key, digest := range digests {
+			if key.chatID == oldChatID {
+				delete(digests, key)
+				requeue(digests, targetKey{chatID: newChatID, target: key.target}, digest)
+			}
+		}
+	}
+	// Выполняющиеся отправки переходят на новый ID сами, когда освободятся.
+	for _, job := range d.sinkJobs {
+		if !job.running && job.ChatID == oldChatID {
+			job.ChatID = newChatID
+		}
+	}
+	for _, job := range d.emailJobs {
+		if !job.running && job.ChatID == oldChatID {
+			job.ChatID = newChatID
+		}
+	}
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	if err := d.store.MovePending(ctx, oldChatID, newChatID); err != nil {
+		log.Printf("Failed to move pending notifications of chat %d to %d: %v", oldChatID, newChatID, err)
+	}
+	if err := d.store.MoveDeadLetters(ctx, oldChatID, newChatID); err != nil {
+		log.Printf("Failed to move dead letters of chat %d to %d: %v", oldChatID, newChatID, err)
+	}
+}
+
+// currentChat возвращает ID, под которым чат живёт сейчас. Должен вызываться под d.mu.
+func (d *Dispatcher) currentChat(chatID int64) int64 {
+	if newChatID, ok := d.migrated[chatID]; ok {
+		return newChatID
+	}
+	return chatID
+}
+
+// flush отправляет сводку по группам. Возвращает записи отправленных групп и, если отправка
+// прервалась, сводку из групп, которые ещё не отправлены.
+func (d *Dispatcher) flush(ctx context.Context, chatID int64, digest *pendingDigest) ([]int64, *pendingDigest, error) {
+	config, _ := d.telegramBot.GetConfig(chatID)
+
+	groups := make(map[string][]int)
+	for i, item := range digest.items {
+		key := item.Account
+		if config.Digest.GroupBy == models.DigestGroupRepo && item.Repo != "" {
+			key = item.Account + "/" + item.Repo
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sent []int64
+	for n, key := range keys {
+		group := make([]Notification, 0, len(groups[key]))
+		for _, i := range groups[key] {
+			group = append(group, digest.items[i])
+		}
+
+		repo := ""
+		if config.Digest.GroupBy == models.DigestGroupRepo {
+			repo = group[0].Repo
+		}
+		threadID := d.telegramBot.ThreadFor(chatID, group[0].Account, repo)
+
+		for _, text := range splitMessage(formatDigest(key, config.Digest.Mode, group), maxMessageLength) {
+			if err := d.deliver(ctx, chatID, config, threadID, text); err != nil {
+				unsent := &pendingDigest{due: digest.due}
+				for _, key := range keys[n:] {
+					for _, i := range groups[key] {
+						unsent.add(digest.ids[i], digest.items[i])
+					}
+				}
+				return sent, unsent, err
+			}
+		}
+		for _, i := range groups[key] {
+			sent = append(sent, digest.ids[i])
+		}
+	}
+
+	log.Printf("Sent digest with %d events to chat %d", len(digest.items), chatID)
+	return sent, nil, nil
+}
+
+// deliverNow отправляет сообщение и ждёт, пока его примет Telegram. Сообщение, на котором
+// отправитель исчерпал попытки, уже сохранено в недоставленных, поэтому ошибкой не считается.
+func (d *Dispatcher) deliverNow(ctx context.Context, chatID int64, threadID int, text string, silent bool) error {
+	msg := telegram.OutgoingMessage{
+		ChatID:   chatID,
+		ThreadID: threadID,
+		Text:     text,
+		Silent:   silent,
+	}
+	err := d.telegramBot.Deliver(ctx, msg)
+	var failure telegram.DeliveryFailure
+	if errors.As(err, &failure) {
+		return nil
+	}
+	return err
+}
+
+// digestPeriod подписывает сводку по её режиму.
+func digestPeriod(mode string) string {
+	if mode == models.DigestDaily {
+		return "за день"
+	}
+	return "за час"
+}
+
+func formatDigest(title, mode string, items []Notification) string {
+	period := digestPeriod(mode)
+
+	byType := make(map[models.EventType][]string)
+	for _, item := range items {
+		byType[item.Type] = append(byType[item.Type], formatLine(item))
+	}
+
+	var b strings.Builder
+	b.WriteString("📋 Сводка " + period + ": <b>" + title + "</b>\n")
+	for _, eventType := range eventOrder {
+		lines := byType[eventType]
+		if len(lines) == 0 {
+			continue
+		}
+		b.WriteString("\n" + eventTitles[eventType] + ":\n")
+		for _, line := range lines {
+			b.WriteString("• " + line + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// nextDigestTime считает время отправки сводки; ежедневная сводка привязана к часовому поясу чата.
+func nextDigestTime(now time.Time, config models.MonitoringConfig) time.Time {
+	if config.Digest.Mode != models.DigestDaily {
+		return now.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	at, err := time.Parse("15:04", config.Digest.At)
+	if err != nil {
+		at, _ = time.Parse("15:04", "09:00")
+	}
+
+	now = now.In(config.Location())
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SplitMessage делит длинный текст на сообщения, укладывающиеся в лимит Telegram.
+func SplitMessage(text string) []string {
+	return splitMessage(text, maxMessageLength)
+}
+
+// splitMessage режет текст по строкам так, чтобы каждая часть укладывалась в лимит Telegram.
+func splitMessage(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if current.Len()+len(line) > limit && current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
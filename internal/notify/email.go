@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// Шифрование соединения с SMTP сервером.
+const (
+	SMTPStartTLS = "starttls" // обычное соединение, затем STARTTLS; как правило, порт 587
+	SMTPImplicit = "implicit" // TLS с первого байта; как правило, порт 465
+	SMTPNone     = "none"     // без шифрования, только для локального релея
+)
+
+// SMTPConfig - параметры почтового сервера для сводок по email.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string // SMTPStartTLS, SMTPImplicit или SMTPNone
+}
+
+// Mailer отправляет письма через SMTP.
+type Mailer struct {
+	config    SMTPConfig
+	tlsConfig *tls.Config // если nil, сертификат сервера проверяется по системным корневым
+}
+
+// MailerFromEnv настраивает отправку почты по переменным SMTP_*.
+// Без SMTP_HOST почта выключена и возвращается nil.
+func MailerFromEnv() (*Mailer, error) {
+	config := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+		TLS:      strings.ToLower(os.Getenv("SMTP_TLS")),
+	}
+	if config.Host == "" {
+		return nil, nil
+	}
+
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		parsed, err := strconv.Atoi(port)
+		if err != nil || parsed <= 0 {
+			return nil, errors.New("SMTP_PORT must be a valid port number")
+		}
+		config.Port = parsed
+	}
+
+	// По умолчанию порт 465 ожидает TLS сразу, остальные - STARTTLS.
+	// SMTP_STARTTLS=false - прежний способ отключить шифрование.
+	if config.TLS == "" {
+		switch {
+		case os.Getenv("SMTP_STARTTLS") == "false":
+			config.TLS = SMTPNone
+		case config.Port == 465:
+			config.TLS = SMTPImplicit
+		default:
+			config.TLS = SMTPStartTLS
+		}
+	}
+	switch config.TLS {
+	case SMTPStartTLS, SMTPImplicit:
+	case SMTPNone:
+		// net/smtp не передаёт пароль без шифрования никуда, кроме localhost: лучше сказать об этом при запуске,
+		// чем на первой сводке.
+		if config.Username != "" && !isLocalhost(config.Host) {
+			return nil, errors.New("SMTP_USERNAME requires SMTP_TLS=starttls or implicit for a remote SMTP_HOST")
+		}
+	default:
+		return nil, fmt.Errorf("SMTP_TLS must be %s, %s or %s", SMTPStartTLS, SMTPImplicit, SMTPNone)
+	}
+
+	if config.From == "" {
+		config.From = config.Username
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("SMTP_FROM must be a valid address: %w", err)
+	}
+
+	return NewMailer(config), nil
+}
+
+func NewMailer(config SMTPConfig) *Mailer {
+	if config.TLS == "" {
+		config.TLS = SMTPStartTLS
+	}
+	return &Mailer{config: config}
+}
+
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// Send отправляет письмо из текстовой и HTML-версии. Если шифрование включено,
+// без него письмо не отправляется, чтобы не передавать пароль открытым текстом.
+func (m *Mailer) Send(ctx context.Context, to, subject, text, htmlBody string) error {
+	message, err := buildMessage(m.config.From, to, subject, text, htmlBody, time.Now())
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	var conn net.Conn
+	if m.config.TLS == SMTPImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.clientTLS()}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.config.TLS == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(m.clientTLS()); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if m.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (m *Mailer) clientTLS() *tls.Config {
+	if m.tlsConfig != nil {
+		return m.tlsConfig.Clone()
+	}
+	return &tls.Config{ServerName: m.config.Host}
+}
+
+// buildMessage собирает письмо multipart/alternative: почтовые клиенты показывают HTML,
+// а текстовая часть остаётся для тех, кто HTML не отображает.
+func buildMessage(from, to, subject, text, htmlBody string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// formatEmailDigest собирает сводку для письма из тех же строк, что и сводка в Telegram.
+func formatEmailDigest(title, mode string, items []Notification) (subject, text, htmlBody string) {
+	subject = "Сводка GitHub " + digestPeriod(mode) + ": " + title
+
+	byType := make(map[models.EventType][]Notification)
+	for _, item := range items {
+		byType[item.Type] = append(byType[item.Type], item)
+	}
+
+	var plain strings.Builder
+	plain.WriteString(subject + "\n")
+	for _, eventType := range eventOrder {
+		group := byType[eventType]
+		if len(group) == 0 {
+			continue
+		}
+		plain.WriteString("\n" + eventTitles[eventType] + ":\n")
+		for _, item := range group {
+			sum := summarize(item)
+			plain.WriteString("• " + sum.Title)
+			if sum.URL != "" {
+				plain.WriteString(" - " + sum.URL)
+			}
+			plain.WriteString("\n")
+		}
+	}
+
+	htmlBody = "<!DOCTYPE html>\n<html><body style=\"font-family: sans-serif; line-height: 1.5\">\n" +
+		strings.ReplaceAll(formatDigest(title, mode, items), "\n", "<br>\n") +
+		"</body></html>\n"
+
+	return subject, plain.String(), htmlBody
+}
@@ -0,0 +1,240 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/storage"
+)
+
+const (
+	// Неудачная отправка письма повторяется с удвоением паузы; после maxEmailAttempts попыток сводка
+	// переносится в очередь недоставленных.
+	emailRetryBase   = 5 * time.Minute
+	emailRetryMax    = 2 * time.Hour
+	maxEmailAttempts = 6
+)
+
+// ForEmail возвращает Notifier, который копит события для подтверждённого адреса и отправляет их
+// одним письмом по расписанию получателя.
+func (d *Dispatcher) ForEmail(chatID int64, recipient models.EmailRecipient) Notifier {
+	return emailNotifier{dispatcher: d, chatID: chatID, recipient: recipient}
+}
+
+// EmailEnabled сообщает, настроена ли отправка почты.
+func (d *Dispatcher) EmailEnabled() bool {
+	return d.mailer != nil
+}
+
+type emailNotifier struct {
+	dispatcher *Dispatcher
+	chatID     int64
+	recipient  models.EmailRecipient
+}
+
+func (e emailNotifier) Notify(_ context.Context, n Notification) error {
+	if !e.recipient.Confirmed {
+		return nil
+	}
+	d := e.dispatcher
+	key := targetKey{chatID: e.chatID, target: strings.ToLower(e.recipient.Address)}
+
+	// Расписание получателя считается в часовом поясе чата.
+	config, _ := d.telegramBot.GetConfig(e.chatID)
+	config.Digest = models.DigestSettings{Mode: e.recipient.Mode, At: e.recipient.At}
+
+	n = compact(n)
+	due := nextDigestTime(time.Now(), config)
+	id, err := d.persist(storage.PendingNotification{Kind: storage.PendingEmailDigest, ChatID: e.chatID, Target: key.target, Due: due}, n)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	digest, exists := d.emails[key]
+	if !exists {
+		digest = &pendingDigest{due: due}
+		d.emails[key] = digest
+	}
+	digest.add(id, n)
+	return nil
+}
+
+// SendConfirmation отправляет письмо с кодом подтверждения адреса для /email.
+func (d *Dispatcher) SendConfirmation(ctx context.Context, to, code string) error {
+	if d.mailer == nil {
+		return errors.New("SMTP is not configured")
+	}
+	text := "Этот адрес добавили для сводок событий GitHub в Telegram-боте.\n\n" +
+		"Чтобы получать сводки, отправьте боту команду:\n/email confirm " + code + "\n\n" +
+		"Если вы не добавляли адрес, просто проигнорируйте письмо: без подтверждения сводки не придут."
+	htmlBody := "<p>Этот адрес добавили для сводок событий GitHub в Telegram-боте.</p>" +
+		"<p>Чтобы получать сводки, отправьте боту команду:<br><code>/email confirm " + html.EscapeString(code) + "</code></p>" +
+		"<p>Если вы не добавляли адрес, просто проигнорируйте письмо: без подтверждения сводки не придут.</p>"
+	return d.mailer.Send(ctx, to, "Подтверждение адреса для сводок GitHub", text, htmlBody)
+}
+
+// emailJob - почтовая сводка, готовая к отправке. Хранится в PendingStore,
+// поэтому переживает перезапуск и повторяется при ошибках SMTP.
+type emailJob struct {
+	ID       int64          `json:"-"`
+	ChatID   int64          `json:"-"`
+	Target   string         `json:"-"` // адрес в нижнем регистре
+	Due      time.Time      `json:"-"`
+	Attempts int            `json:"-"`
+	Items    []Notification `json:"items"`
+
+	running bool
+}
+
+// queueEmail превращает накопленную сводку в отправку. Записи сводки удаляются
+// после того, как сохранена отправка, поэтому сбой между шагами не теряет события.
+func (d *Dispatcher) queueEmail(key targetKey, digest *pendingDigest) error {
+	if err := d.queueEmailJob(&emailJob{ChatID: key.chatID, Target: key.target, Items: digest.items}); err != nil {
+		return err
+	}
+	d.forget(digest.ids)
+	return nil
+}
+
+// queueEmailJob сохраняет отправку письма и ставит её в очередь.
+func (d *Dispatcher) queueEmailJob(job *emailJob) error {
+	job.Due = time.Now()
+	id, err := d.persist(storage.PendingNotification{Kind: storage.PendingEmail, ChatID: job.ChatID, Target: job.Target, Due: job.Due}, job)
+	if err != nil {
+		return err
+	}
+	job.ID = id
+
+	d.mu.Lock()
+	d.emailJobs = append(d.emailJobs, job)
+	d.mu.Unlock()
+	return nil
+}
+
+// startEmails запускает отправки, время которых пришло.
+func (d *Dispatcher) startEmails(now time.Time) {
+	var ready []*emailJob
+
+	d.mu.Lock()
+	ctx := d.ctx
+	if ctx.Err() != nil {
+		d.mu.Unlock()
+		return
+	}
+	for _, job := range d.emailJobs {
+		if job.running || now.Before(job.Due) {
+			continue
+		}
+		job.running = true
+		ready = append(ready, job)
+	}
+	d.mu.Unlock()
+
+	for _, job := range ready {
+		go d.runEmail(ctx, job)
+	}
+}
+
+func (d *Dispatcher) runEmail(ctx context.Context, job *emailJob) {
+	err := d.sendEmail(ctx, job)
+	if err == nil {
+		d.finishEmail(job)
+		return
+	}
+
+	d.mu.Lock()
+	// Отправку прервала остановка: попыткой это не считается, после перезапуска она повторится.
+	if ctx.Err() != nil {
+		job.running = false
+		d.mu.Unlock()
+		return
+	}
+	job.Attempts++
+	attempts := job.Attempts
+	job.ChatID = d.currentChat(job.ChatID)
+	d.mu.Unlock()
+
+	if attempts >= maxEmailAttempts {
+		log.Printf("Giving up on email digest with %d events for chat %d after %d attempts: %v", len(job.Items), job.ChatID, attempts, err)
+		d.bury(models.DeadLetterEmail, job.ChatID, job.Target, deadJob{Target: job.Target, Items: job.Items}, attempts, err)
+		d.finishEmail(job)
+		return
+	}
+
+	due := time.Now().Add(min(emailRetryBase<<(attempts-1), emailRetryMax))
+	log.Printf("Failed to send email digest for chat %d, retrying at %s: %v", job.ChatID, due.Format(time.RFC3339), err)
+	d.reschedule(job.ID, due, attempts)
+
+	d.mu.Lock()
+	job.Due = due
+	job.running = false
+	d.mu.Unlock()
+}
+
+// sendEmail отправляет сводку. Пока она копилась, адрес могли удалить из подписки
+// или отключить SMTP - тогда отправлять некуда.
+func (d *Dispatcher) sendEmail(ctx context.Context, job *emailJob) error {
+	if d.mailer == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	chatID := d.currentChat(job.ChatID)
+	d.mu.Unlock()
+
+	config, _ := d.telegramBot.GetConfig(chatID)
+	var recipient *models.EmailRecipient
+	for i := range config.Emails {
+		if strings.EqualFold(config.Emails[i].Address, job.Target) && config.Emails[i].Confirmed {
+			recipient = &config.Emails[i]
+		}
+	}
+	if recipient == nil {
+		return nil
+	}
+
+	subject, text, htmlBody := formatEmailDigest(digestAccounts(job.Items), recipient.Mode, job.Items)
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	if err := d.mailer.Send(ctx, recipient.Address, subject, text, htmlBody); err != nil {
+		return err
+	}
+	log.Printf("Sent email digest with %d events for chat %d", len(job.Items), job.ChatID)
+	return nil
+}
+
+func (d *Dispatcher) finishEmail(job *emailJob) {
+	d.mu.Lock()
+	for i, queued := range d.emailJobs {
+		if queued == job {
+			d.emailJobs = append(d.emailJobs[:i], d.emailJobs[i+1:]...)
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	d.forget([]int64{job.ID})
+}
+
+// digestAccounts перечисляет аккаунты сводки для её заголовка.
+func digestAccounts(items []Notification) string {
+	var accounts []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if !seen[item.Account] {
+			seen[item.Account] = true
+			accounts = append(accounts, item.Account)
+		}
+	}
+	return strings.Join(accounts, ", ")
+}
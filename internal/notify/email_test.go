@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpServer - SMTP сервер-заглушка: принимает одно письмо и запоминает, что видел.
+type smtpServer struct {
+	mode      string // SMTPStartTLS, SMTPImplicit или SMTPNone
+	tlsConfig *tls.Config
+
+	mu       sync.Mutex
+	auth     string // имя пользователя из AUTH PLAIN
+	authTLS  bool   // AUTH пришёл по зашифрованному соединению
+	rcpt     string
+	data     string
+	finished chan struct{}
+}
+
+// testTLS возвращает конфигурацию сервера с сертификатом для 127.0.0.1 и пул корневых сертификатов для клиента.
+func testTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return server.TLS.Clone(), roots
+}
+
+// startSMTP запускает заглушку и возвращает Mailer, настроенный на неё.
+func startSMTP(t *testing.T, mode, username string) (*smtpServer, *Mailer) {
+	t.Helper()
+	serverTLS, roots := testTLS(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &smtpServer{mode: mode, tlsConfig: serverTLS, finished: make(chan struct{})}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer close(server.finished)
+		server.serve(conn)
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	mailer := NewMailer(SMTPConfig{Host: "127.0.0.1", Port: port, Username: username, Password: "secret", From: "bot@example.com", TLS: mode})
+	mailer.tlsConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+	return server, mailer
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	encrypted := false
+	if s.mode == SMTPImplicit {
+		conn = tls.Server(conn, s.tlsConfig)
+		encrypted = true
+	}
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 127.0.0.1 ESMTP test")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-127.0.0.1")
+			if s.mode == SMTPStartTLS && !encrypted {
+				text.PrintfLine("250-STARTTLS")
+			}
+			text.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			text.PrintfLine("220 Ready to start TLS")
+			conn = tls.Server(conn, s.tlsConfig)
+			text = textproto.NewConn(conn)
+			encrypted = true
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(decoded), "\x00")
+			s.mu.Lock()
+			if len(parts) == 3 {
+				s.auth = parts[1]
+			}
+			s.authTLS = encrypted
+			s.mu.Unlock()
+			text.PrintfLine("235 Authentication successful")
+		case "MAIL":
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpt = arg
+			s.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			text.PrintfLine("250 Queued")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func sendTestEmail(t *testing.T, server *smtpServer, mailer *Mailer) {
+	t.Helper()
+	if err := mailer.Send(context.Background(), "user@example.com", "Сводка", "text body", "<p>html body</p>"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-server.finished
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.rcpt != "TO:<user@example.com>" {
+		t.Errorf("RCPT = %q", server.rcpt)
+	}
+	if !strings.Contains(server.data, "To: user@example.com") || !strings.Contains(server.data, "text body") {
+		t.Errorf("unexpected message:\n%s", server.data)
+	}
+}
+
+func TestMailerPlain(t *testing.T) {
+	server, mailer := startSMTP(t, SMTPNone, "")
+	sendTestEmail(t, server, mailer)
+	if server.auth != "" {
+		t.Errorf("unexpected AUTH as %q", server.auth)
+	}
+}
+
+func TestMailerStartTLS(t *testing.T) {
+	server, mailer := startSMTP(t, SMTPStartTLS, "bot")
+	sendTestEmail(t, server, mailer)
+	if server.auth != "bot" || !server.authTLS {
+		t.Errorf("AUTH as %q, encrypted %v; want bot over TLS", server.auth, server.authTLS)
+	}
+}
+
+func TestMailerImplicitTLS(t *testing.T) {
+	server, mailer := startSMTP(t, SMTPImplicit, "bot")
+	sendTestEmail(t, server, mailer)
+	if server.auth != "bot" || !server.authTLS {
+		t.Errorf("AUTH as %q, encrypted %v; want bot over TLS", server.auth, server.authTLS)
+	}
+}
+
+func TestMailerRequiresStartTLS(t *testing.T) {
+	// Сервер без STARTTLS, а клиент требует шифрования: пароль не должен уйти открытым текстом.
+	server, mailer := startSMTP(t, SMTPNone, "bot")
+	mailer.config.TLS = SMTPStartTLS
+
+	err := mailer.Send(context.Background(), "user@example.com", "Сводка", "text", "<p>html</p>")
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected STARTTLS error, got %v", err)
+	}
+	<-server.finished
+	if server.auth != "" {
+		t.Error("credentials were sent without TLS")
+	}
+}
+
+func TestMailerFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		tls  string
+		ok   bool
+	}{
+		{"default", map[string]string{}, SMTPStartTLS, true},
+		{"port 465", map[string]string{"SMTP_PORT": "465"}, SMTPImplicit, true},
+		{"explicit", map[string]string{"SMTP_PORT": "2525", "SMTP_TLS": "implicit"}, SMTPImplicit, true},
+		{"legacy", map[string]string{"SMTP_HOST": "localhost", "SMTP_STARTTLS": "false"}, SMTPNone, true},
+		{"plain auth to remote", map[string]string{"SMTP_TLS": "none"}, "", false},
+		{"unknown", map[string]string{"SMTP_TLS": "ssl"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SMTP_HOST", "smtp.example.com")
+			t.Setenv("SMTP_USERNAME", "bot@example.com")
+			for _, name := range []string{"SMTP_PORT", "SMTP_TLS", "SMTP_STARTTLS", "SMTP_FROM", "SMTP_PASSWORD"} {
+				t.Setenv(name, "")
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			mailer, err := MailerFromEnv()
+			if (err == nil) != tt.ok {
+				t.Fatalf("MailerFromEnv() error = %v, want ok=%v", err, tt.ok)
+			}
+			if tt.ok && mailer.config.TLS != tt.tls {
+				t.Errorf("TLS = %q, want %q", mailer.config.TLS, tt.tls)
+			}
+		})
+	}
+}
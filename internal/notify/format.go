@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// formatText собирает полное сообщение для мгновенной доставки.
+// Даты показываются в часовом поясе чата.
+func formatText(n Notification, config models.MonitoringConfig) string {
+	switch n.Type {
+	case models.EventNewRepo:
+		repo := n.Repository
+		text := "🆕 Обнаружен новый репозиторий:\n"
+		text += "• " + html.EscapeString(repo.Name) + " - " + html.EscapeString(repo.Description) + "\n"
+		text += "  URL: " + repo.URL + "\n"
+		return text
+
+	case models.EventCommit:
+		commit := n.Commit
+		text := "📝 Новый коммит в репозитории " + html.EscapeString(n.Repo) + ":\n"
+		text += "• Сообщение: " + html.EscapeString(commit.Message) + "\n"
+		text += "• Автор: " + html.EscapeString(commit.Author) + "\n"
+		text += "• Дата: " + config.FormatTime(commit.Date) + "\n"
+		text += "• URL: " + commit.URL + "\n"
+		return text
+
+	case models.EventRelease:
+		release := n.Release
+		text := "🏷 Новый релиз в репозитории " + html.EscapeString(n.Repo) + ":\n"
+		text += "• Версия: " + html.EscapeString(release.TagName)
+		if release.Prerelease {
+			text += " (pre-release)"
+		}
+		text += "\n"
+		if release.Name != "" && release.Name != release.TagName {
+			text += "• Название: " + html.EscapeString(release.Name) + "\n"
+		}
+		text += "• Дата: " + config.FormatTime(release.PublishedAt) + "\n"
+		text += "• URL: " + release.URL + "\n"
+		return text
+
+	case models.EventPullRequest:
+		pull := n.PullRequest
+		text := pullRequestTitles[n.Action] + " в репозитории " + html.EscapeString(n.Repo) + ":\n"
+		text += "• #" + strconv.Itoa(pull.Number) + " " + html.EscapeString(pull.Title)
+		if pull.Draft {
+			text += " (черновик)"
+		}
+		text += "\n"
+		text += "• Автор: " + html.EscapeString(pull.Author) + "\n"
+		if pull.Base != "" {
+			text += "• Ветка: " + html.EscapeString(pull.Base) + "\n"
+		}
+		text += "• URL: " + pull.URL + "\n"
+		return text
+
+	case models.EventCI:
+		ci := n.CI
+		text := ciTitle(ci) + " в репозитории " + html.EscapeString(n.Repo) + ":\n"
+		text += "• Коммит: " + html.EscapeString(ci.Message) + " (" + shortSHA(ci.SHA) + ")\n"
+		text += "• Автор: " + html.EscapeString(ci.Author) + "\n"
+		if len(ci.Failed) > 0 {
+			text += "• Упали: " + html.EscapeString(strings.Join(ci.Failed, ", ")) + "\n"
+		}
+		text += "• URL: " + ci.URL + "\n"
+		return text
+
+	case models.EventDependency:
+		commit := n.Commit
+		text := "📦 Изменены зависимости в репозитории " + html.EscapeString(n.Repo) + ":\n"
+		for _, manifest := range n.Manifests {
+			text += "\n<b>" + html.EscapeString(manifest.File) + "</b> (" + manifest.Ecosystem + "):\n"
+			for _, change := range manifest.Changes {
+				text += html.EscapeString(describeChange(change)) + "\n"
+			}
+		}
+		text += "\n• Коммит: " + html.EscapeString(strings.SplitN(commit.Message, "\n", 2)[0]) + "\n"
+		text += "• Автор: " + html.EscapeString(commit.Author) + "\n"
+		text += "• URL: " + commit.URL + "\n"
+		return text
+	}
+
+	return ""
+}
+
+// pullRequestActions - действие с PR для строки сводки.
+var pullRequestActions = map[string]string{
+	models.PullRequestOpened: "открыт",
+	models.PullRequestMerged: "влит",
+	models.PullRequestClosed: "закрыт",
+}
+
+var pullRequestTitles = map[string]string{
+	models.PullRequestOpened: "🔀 Открыт pull request",
+	models.PullRequestMerged: "🟣 Влит pull request",
+	models.PullRequestClosed: "🚫 Закрыт pull request",
+}
+
+// ciTitle описывает итог проверок без разметки: текст общий для Telegram, писем и внешних получателей.
+func ciTitle(ci *models.CIStatus) string {
+	if ci.State == models.CISuccess {
+		return "✅ CI пройден"
+	}
+	return fmt.Sprintf("❌ CI упал (%d из %d)", len(ci.Failed), ci.Total)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// describeChange описывает изменение зависимости без разметки: текст общий для Telegram,
+// писем и внешних получателей.
+func describeChange(change models.DependencyChange) string {
+	switch change.Kind {
+	case models.DependencyAdded:
+		return strings.TrimSpace("➕ " + change.Name + " " + change.To)
+	case models.DependencyRemoved:
+		return strings.TrimSpace("➖ " + change.Name + " " + change.From)
+	}
+	return "🔄 " + change.Name + " " + change.From + " → " + change.To
+}
+
+func countChanges(manifests []models.ManifestChange) (added, removed, updated int) {
+	for _, manifest := range manifests {
+		for _, change := range manifest.Changes {
+			switch change.Kind {
+			case models.DependencyAdded:
+				added++
+			case models.DependencyRemoved:
+				removed++
+			default:
+				updated++
+			}
+		}
+	}
+	return added, removed, updated
+}
+
+// formatAlert добавляет к сообщению заметный заголовок срочного уведомления.
+// Для чата эскалации указывается, из какого чата пришло событие.
+func formatAlert(n Notification, config models.MonitoringConfig, sourceChatID int64) string {
+	header := "🚨 <b>ВАЖНО</b>: совпадение с правилом <code>" + html.EscapeString(n.Alert) + "</code>\n"
+	if sourceChatID != 0 {
+		header += "Аккаунт " + html.EscapeString(n.Account) + ", чат " + strconv.FormatInt(sourceChatID, 10) + "\n"
+	}
+	return header + "\n" + formatText(n, config)
+}
+
+// formatSecretAlert перечисляет найденные секреты; сами значения уже замаскированы.
+func formatSecretAlert(n Notification, findings []models.SecretFinding, sourceChatID int64) string {
+	commit := n.Commit
+	sha := shortSHA(commit.SHA)
+
+	var b strings.Builder
+	b.WriteString("🔐 <b>ВОЗМОЖНАЯ УТЕЧКА СЕКРЕТА</b> в репозитории " + html.EscapeString(n.Account+"/"+n.Repo) + "\n")
+	if sourceChatID != 0 {
+		b.WriteString("Чат " + strconv.FormatInt(sourceChatID, 10) + "\n")
+	}
+	b.WriteString(`Коммит <a href="` + commit.URL + `">` + sha + `</a> (` + html.EscapeString(commit.Author) + ")\n\n")
+
+	for _, finding := range findings {
+		b.WriteString("• " + html.EscapeString(finding.File) + ":" + strconv.Itoa(finding.Line) +
+			" - " + html.EscapeString(finding.Description) + " [" + finding.Rule + "]\n")
+		b.WriteString("  <code>" + html.EscapeString(finding.Snippet) + "</code>\n")
+	}
+
+	b.WriteString("\nСмените скомпрометированные ключи: удаление коммита не отменяет утечку.\n")
+	b.WriteString("Ложное срабатывание: /secrets ignore " + html.EscapeString(n.Repo) + " <правило> [путь]")
+	return b.String()
+}
+
+// formatLine собирает краткую строку для сводки.
+func formatLine(n Notification) string {
+	switch n.Type {
+	case models.EventNewRepo:
+		repo := n.Repository
+		line := `<a href="` + repo.URL + `">` + html.EscapeString(repo.Name) + `</a>`
+		if repo.Description != "" {
+			line += " - " + html.EscapeString(repo.Description)
+		}
+		return line
+
+	case models.EventCommit:
+		commit := n.Commit
+		summary := strings.SplitN(commit.Message, "\n", 2)[0]
+		return html.EscapeString(n.Repo) + `: <a href="` + commit.URL + `">` + html.EscapeString(summary) + `</a>` +
+			` (` + html.EscapeString(commit.Author) + `)`
+
+	case models.EventRelease:
+		release := n.Release
+		return html.EscapeString(n.Repo) + `: <a href="` + release.URL + `">` + html.EscapeString(release.TagName) + `</a>`
+
+	case models.EventPullRequest:
+		pull := n.PullRequest
+		return html.EscapeString(n.Repo) + `: <a href="` + pull.URL + `">#` + strconv.Itoa(pull.Number) + " " + html.EscapeString(pull.Title) + `</a>` +
+			` (` + pullRequestActions[n.Action] + `, ` + html.EscapeString(pull.Author) + `)`
+
+	case models.EventCI:
+		ci := n.CI
+		mark := "✅"
+		if ci.State != models.CISuccess {
+			mark = "❌"
+		}
+		return html.EscapeString(n.Repo) + `: ` + mark + ` <a href="` + ci.URL + `">` + html.EscapeString(ci.Message) + `</a>`
+
+	case models.EventDependency:
+		added, removed, changed := countChanges(n.Manifests)
+		return html.EscapeString(n.Repo) + `: <a href="` + n.Commit.URL + `">` +
+			fmt.Sprintf("+%d −%d 🔄%d", added, removed, changed) + `</a>`
+	}
+
+	return ""
+}
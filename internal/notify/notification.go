@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+type Notification struct {
+	Type        models.EventType
+	Account     string
+	Repo        string
+	Repository  *models.Repository      // для EventNewRepo
+	Commit      *models.Commit          // для EventCommit и EventDependency
+	Release     *models.Release         // для EventRelease
+	PullRequest *models.PullRequest     // для EventPullRequest
+	Action      string                  // для EventPullRequest: opened, merged или closed
+	CI          *models.CIStatus        // для EventCI
+	Manifests   []models.ManifestChange // для EventDependency
+	Time        time.Time
+	Alert       string // сработавшее правило /alert, если событие срочное
+}
+
+// FromEvent превращает событие из шины в уведомление для форматирования и доставки.
+func FromEvent(event models.Event) Notification {
+	source := event.Source()
+	n := Notification{
+		Type:    event.Kind(),
+		Account: source.Account,
+		Repo:    source.Repo,
+		Time:    source.DetectedAt,
+	}
+
+	switch e := event.(type) {
+	case models.RepoCreated:
+		n.Repository = &e.Repository
+	case models.CommitPushed:
+		n.Commit = &e.Commit
+	case models.ReleasePublished:
+		n.Release = &e.Release
+	case models.PullRequestChanged:
+		n.PullRequest = &e.PullRequest
+		n.Action = e.Action
+	case models.CICompleted:
+		n.CI = &e.Status
+	}
+	return n
+}
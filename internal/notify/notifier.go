@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Notifier доставляет уведомление в один канал: чат Telegram, Slack, Discord или произвольный webhook.
+// Подписка может отправлять уведомления сразу в несколько каналов.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// ForChat возвращает Notifier чата Telegram: уведомления проходят через сводки и тихие часы чата.
+func (d *Dispatcher) ForChat(chatID int64) Notifier {
+	return chatNotifier{dispatcher: d, chatID: chatID}
+}
+
+type chatNotifier struct {
+	dispatcher *Dispatcher
+	chatID     int64
+}
+
+func (c chatNotifier) Notify(ctx context.Context, n Notification) error {
+	return c.dispatcher.Dispatch(ctx, c.chatID, n)
+}
+
+// DigestNotifier - внешний получатель, который принимает и отдельные уведомления, и сводку одним сообщением.
+type DigestNotifier interface {
+	Notifier
+	NotifyDigest(ctx context.Context, title, mode string, items []Notification) error
+}
+
+// NewNotifier создаёт Notifier для внешнего получателя из настроек подписки.
+// Адрес проверяется ещё раз: в сохранённых настройках могли остаться получатели,
+// добавленные до появления проверок.
+func NewNotifier(sink models.Sink, client *http.Client) (DigestNotifier, error) {
+	if err := sink.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch sink.Kind {
+	case models.SinkSlack:
+		return &SlackNotifier{URL: sink.URL, Client: client}, nil
+	case models.SinkDiscord:
+		return &DiscordNotifier{URL: sink.URL, Client: client}, nil
+	case models.SinkWebhook:
+		return &WebhookNotifier{URL: sink.URL, Client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown sink kind %q", sink.Kind)
+}
@@ -0,0 +1,245 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/storage"
+)
+
+// Время ожидания хранилища отложенных уведомлений.
+const storeTimeout = 10 * time.Second
+
+// persist сохраняет отложенное уведомление, чтобы оно пережило перезапуск; payload кодируется в JSON.
+// Уведомление, которое не удалось сохранить, в очередь не ставится: вызывающий повторит его сам.
+func (d *Dispatcher) persist(item storage.PendingNotification, payload any) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode %s notification for chat %d: %w", item.Kind, item.ChatID, err)
+	}
+	item.Payload = data
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	return d.store.AddPending(ctx, item)
+}
+
+// reschedule переносит сохранённую отправку после неудачной попытки.
+func (d *Dispatcher) reschedule(id int64, due time.Time, attempts int) {
+	if id == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := d.store.ReschedulePending(ctx, id, due, attempts); err != nil {
+		log.Printf("Failed to reschedule notification %d: %v", id, err)
+	}
+}
+
+// forget удаляет из хранилища отправленные уведомления.
+func (d *Dispatcher) forget(ids []int64) {
+	stored := ids[:0:0]
+	for _, id := range ids {
+		if id != 0 {
+			stored = append(stored, id)
+		}
+	}
+	if len(stored) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := d.store.DeletePending(ctx, stored); err != nil {
+		log.Printf("Failed to delete %d sent notifications: %v", len(stored), err)
+	}
+}
+
+// restore возвращает в очередь уведомления, отложенные до перезапуска.
+func (d *Dispatcher) restore(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	items, err := d.store.LoadPending(ctx)
+	if err != nil {
+		log.Printf("Failed to restore pending notifications: %v", err)
+		return
+	}
+
+	var malformed []int64
+
+	d.mu.Lock()
+	for _, item := range items {
+		switch item.Kind {
+		case storage.PendingDigest:
+			var n Notification
+			if err := json.Unmarshal(item.Payload, &n); err != nil {
+				log.Printf("Unable to decode pending notification %d: %v", item.ID, err)
+				malformed = append(malformed, item.ID)
+				continue
+			}
+			digest, exists := d.digests[item.ChatID]
+			if !exists {
+				digest = &pendingDigest{due: item.Due}
+				d.digests[item.ChatID] = digest
+			}
+			if item.Due.Before(digest.due) {
+				digest.due = item.Due
+			}
+			digest.add(item.ID, n)
+
+		case storage.PendingHeld:
+			var msg heldMessage
+			if err := json.Unmarshal(item.Payload, &msg); err != nil {
+				log.Printf("Unable to decode pending notification %d: %v", item.ID, err)
+				malformed = append(malformed, item.ID)
+				continue
+			}
+			msg.ID = item.ID
+			d.held[item.ChatID] = append(d.held[item.ChatID], msg)
+
+		case storage.PendingSinkDigest:
+			var n Notification
+			if err := json.Unmarshal(item.Payload, &n); err != nil {
+				log.Printf("Unable to decode pending notification %d: %v", item.ID, err)
+				malformed = append(malformed, item.ID)
+				continue
+			}
+			key := targetKey{chatID: item.ChatID, target: sinkID(item.Target)}
+			digest, exists := d.sinkDigests[key]
+			if !exists {
+				digest = &pendingDigest{due: item.Due}
+				d.sinkDigests[key] = digest
+			}
+			if item.Due.Before(digest.due) {
+				digest.due = item.Due
+			}
+			digest.add(item.ID, n)
+
+		case storage.PendingSink:
+			job := &sinkJob{ID: item.ID, ChatID: item.ChatID, Target: sinkID(item.Target), Due: item.Due, Attempts: item.Attempts}
+			if err := json.Unmarshal(item.Payload, job); err != nil || len(job.Items) == 0 {
+				log.Printf("Unable to decode pending notification %d: %v", item.ID, err)
+				malformed = append(malformed, item.ID)
+				continue
+			}
+			d.sinkJobs = append(d.sinkJobs, job)
+
+		case storage.PendingEmailDigest:
+			var n Notification
+			if err := json.Unmarshal(item.Payload, &n); err != nil {
+				log.Printf("Unable to decode pending notification %d: %v", item.ID, err)
+				malformed = append(malformed, item.ID)
+				continue
+			}
+			key := targetKey{chatID: item.ChatID, target: item.Target}
+			digest, exists := d.emails[key]
+			if !exists {
+				digest = &pendingDigest{due: item.Due}
+				d.emails[key] = digest
+			}
+			if item.Due.Before(digest.due) {
+				digest.due = item.Due
+			}
+			digest.add(item.ID, n)
+
+		case storage.PendingEmail:
+			job := &emailJob{ID: item.ID, ChatID: item.ChatID, Target: item.Target, Due: item.Due, Attempts: item.Attempts}
+			if err := json.Unmarshal(item.Payload, job); err != nil || len(job.Items) == 0 {
+				log.Printf("Unable to decode pending notification %d: %v", item.ID, err)
+				malformed = append(malformed, item.ID)
+				continue
+			}
+			d.emailJobs = append(d.emailJobs, job)
+		}
+	}
+	d.mu.Unlock()
+
+	d.forget(malformed)
+	if len(items) > 0 {
+		log.Printf("Restored %d pending notifications", len(items))
+	}
+}
+
+// deadJob - отправка внешнему получателю или письмо, от которых Dispatcher отказался.
+// Сохраняется в недоставленных, чтобы администратор мог поставить её в очередь заново.
+type deadJob struct {
+	Target string         `json:"target"` // адрес почты или models.Sink.ID
+	Items  []Notification `json:"items"`
+	Digest string         `json:"digest,omitempty"`
+	Urgent bool           `json:"urgent,omitempty"`
+}
+
+// bury сохраняет отправку в очереди недоставленных; display - адрес получателя, который можно показать администратору.
+func (d *Dispatcher) bury(channel string, chatID int64, display string, job deadJob, attempts int, cause error) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("Failed to encode undeliverable %s notification for chat %d: %v", channel, chatID, err)
+		return
+	}
+
+	lines := make([]string, 0, len(job.Items))
+	for _, item := range job.Items {
+		lines = append(lines, "• "+formatLine(item))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	letter := models.DeadLetter{
+		Channel:  channel,
+		ChatID:   chatID,
+		Target:   display,
+		Text:     strings.Join(lines, "\n"),
+		Payload:  payload,
+		Error:    cause.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	}
+	if err := d.store.AddDeadLetter(ctx, letter); err != nil {
+		log.Printf("Failed to record undeliverable %s notification for chat %d: %v", channel, chatID, err)
+	}
+}
+
+// Replay ставит в очередь заново отправку внешнему получателю или письмо из очереди недоставленных.
+func (d *Dispatcher) Replay(letter models.DeadLetter) error {
+	var job deadJob
+	if err := json.Unmarshal(letter.Payload, &job); err != nil || len(job.Items) == 0 {
+		return fmt.Errorf("malformed dead letter %d: %v", letter.ID, err)
+	}
+
+	switch letter.Channel {
+	case models.DeadLetterSink:
+		if err := d.queueSink(&sinkJob{ChatID: letter.ChatID, Target: sinkID(job.Target), Items: job.Items, Digest: job.Digest, Urgent: job.Urgent}); err != nil {
+			return err
+		}
+		d.startSinks(time.Now())
+	case models.DeadLetterEmail:
+		if err := d.queueEmailJob(&emailJob{ChatID: letter.ChatID, Target: job.Target, Items: job.Items}); err != nil {
+			return err
+		}
+		d.startEmails(time.Now())
+	default:
+		return fmt.Errorf("dead letter %d has unknown channel %q", letter.ID, letter.Channel)
+	}
+	return nil
+}
+
+// compact убирает из уведомления патчи коммита: в сводке они не нужны, а места занимают много.
+func compact(n Notification) Notification {
+	if n.Commit != nil && len(n.Commit.Files) > 0 {
+		commit := *n.Commit
+		commit.Files = nil
+		n.Commit = &commit
+	}
+	return n
+}
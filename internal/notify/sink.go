@@ -0,0 +1,283 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/storage"
+)
+
+const (
+	// Время ожидания ответа внешнего получателя.
+	sinkTimeout = 10 * time.Second
+
+	// Неудачная отправка повторяется с удвоением паузы; после maxSinkAttempts попыток сообщение
+	// переносится в очередь недоставленных.
+	sinkRetryBase   = time.Minute
+	sinkRetryMax    = time.Hour
+	maxSinkAttempts = 8
+)
+
+// NewSinkClient возвращает HTTP-клиент для внешних получателей. Адрес задаёт пользователь, поэтому
+// клиент не подключается к локальной машине и внутренней сети: проверяется уже разрешённый IP,
+// и DNS-имя, указывающее на 127.0.0.1, запрет не обходит. Прокси из окружения и редиректы
+// не используются по той же причине.
+func NewSinkClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: allowPublicAddr}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func allowPublicAddr(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !models.IsPublicAddr(addr) {
+		return fmt.Errorf("connections to %s are not allowed", addr)
+	}
+	return nil
+}
+
+// sinkJob - отправка внешнему получателю: одно уведомление или сводка.
+// Хранится в PendingStore, поэтому переживает перезапуск и повторяется при ошибках.
+type sinkJob struct {
+	ID       int64          `json:"-"`
+	ChatID   int64          `json:"-"`
+	Target   string         `json:"-"` // models.Sink.ID получателя: адрес с токеном не сохраняется
+	Due      time.Time      `json:"-"`
+	Attempts int            `json:"-"`
+	Items    []Notification `json:"items"`
+	Digest   string         `json:"digest,omitempty"` // режим сводки; пусто - отдельное уведомление
+	Urgent   bool           `json:"urgent,omitempty"`
+
+	running bool
+}
+
+// ForSink возвращает Notifier внешнего получателя из /sink. Уведомление ставится в очередь
+// и отправляется в фоне - с учётом сводок и тихих часов чата, как и уведомления в сам чат.
+func (d *Dispatcher) ForSink(chatID int64, sink models.Sink) Notifier {
+	return sinkNotifier{dispatcher: d, chatID: chatID, sink: sink}
+}
+
+type sinkNotifier struct {
+	dispatcher *Dispatcher
+	chatID     int64
+	sink       models.Sink
+}
+
+func (s sinkNotifier) Notify(_ context.Context, n Notification) error {
+	d := s.dispatcher
+	config, _ := d.telegramBot.GetConfig(s.chatID)
+	n = compact(n)
+
+	if n.Alert == "" && config.Digest.Mode != models.DigestOff && !config.Digest.Excluded[n.Type] {
+		key := targetKey{chatID: s.chatID, target: s.sink.ID()}
+		due := nextDigestTime(time.Now(), config)
+		id, err := d.persist(storage.PendingNotification{Kind: storage.PendingSinkDigest, ChatID: s.chatID, Target: key.target, Due: due}, n)
+		if err != nil {
+			return err
+		}
+
+		d.mu.Lock()
+		digest, exists := d.sinkDigests[key]
+		if !exists {
+			digest = &pendingDigest{due: due}
+			d.sinkDigests[key] = digest
+		}
+		digest.add(id, n)
+		d.mu.Unlock()
+		return nil
+	}
+
+	if err := d.queueSink(&sinkJob{ChatID: s.chatID, Target: s.sink.ID(), Items: []Notification{n}, Urgent: n.Alert != ""}); err != nil {
+		return err
+	}
+	d.startSinks(time.Now())
+	return nil
+}
+
+// queueSink сохраняет отправку и ставит её в очередь.
+func (d *Dispatcher) queueSink(job *sinkJob) error {
+	job.Due = time.Now()
+	id, err := d.persist(storage.PendingNotification{Kind: storage.PendingSink, ChatID: job.ChatID, Target: job.Target, Due: job.Due}, job)
+	if err != nil {
+		return err
+	}
+	job.ID = id
+
+	d.mu.Lock()
+	d.sinkJobs = append(d.sinkJobs, job)
+	d.mu.Unlock()
+	return nil
+}
+
+// queueSinkDigest превращает накопленную сводку в отправку. Записи сводки удаляются
+// после того, как сохранена отправка, поэтому сбой между шагами не теряет события.
+func (d *Dispatcher) queueSinkDigest(key targetKey, digest *pendingDigest) error {
+	config, _ := d.telegramBot.GetConfig(key.chatID)
+	mode := config.Digest.Mode
+	if mode == models.DigestOff {
+		mode = models.DigestHourly
+	}
+
+	if err := d.queueSink(&sinkJob{ChatID: key.chatID, Target: key.target, Items: digest.items, Digest: mode}); err != nil {
+		return err
+	}
+	d.forget(digest.ids)
+	return nil
+}
+
+// startSinks запускает отправки, время которых пришло. Обычные уведомления ждут конца тихих часов,
+// срочные - только конца snooze.
+func (d *Dispatcher) startSinks(now time.Time) {
+	var ready []*sinkJob
+
+	d.mu.Lock()
+	ctx := d.ctx
+	if ctx.Err() != nil {
+		d.mu.Unlock()
+		return
+	}
+	for _, job := range d.sinkJobs {
+		if job.running || now.Before(job.Due) {
+			continue
+		}
+		config, _ := d.telegramBot.GetConfig(job.ChatID)
+		if (job.Urgent && now.Before(config.SnoozeUntil)) || (!job.Urgent && config.QuietMode(now) == models.QuietHold) {
+			continue
+		}
+		job.running = true
+		ready = append(ready, job)
+	}
+	d.mu.Unlock()
+
+	for _, job := range ready {
+		go d.runSink(ctx, job)
+	}
+}
+
+func (d *Dispatcher) runSink(ctx context.Context, job *sinkJob) {
+	err := d.sendSink(ctx, job)
+	if err == nil {
+		d.finishSink(job)
+		return
+	}
+
+	d.mu.Lock()
+	// Отправку прервала остановка: попыткой это не считается, после перезапуска она повторится.
+	if ctx.Err() != nil {
+		job.running = false
+		d.mu.Unlock()
+		return
+	}
+	job.Attempts++
+	attempts := job.Attempts
+	job.ChatID = d.currentChat(job.ChatID)
+	d.mu.Unlock()
+
+	if attempts >= maxSinkAttempts {
+		log.Printf("Giving up on sink of chat %d after %d attempts: %v", job.ChatID, attempts, err)
+		display := "удалённый получатель"
+		if sink, ok := d.findSink(job.ChatID, job.Target); ok {
+			display = sink.MaskedURL()
+		}
+		d.bury(models.DeadLetterSink, job.ChatID, display,
+			deadJob{Target: job.Target, Items: job.Items, Digest: job.Digest, Urgent: job.Urgent}, attempts, err)
+		d.finishSink(job)
+		return
+	}
+
+	due := time.Now().Add(min(sinkRetryBase<<(attempts-1), sinkRetryMax))
+	log.Printf("Failed to notify sink of chat %d, retrying at %s: %v", job.ChatID, due.Format(time.RFC3339), err)
+	d.reschedule(job.ID, due, attempts)
+
+	d.mu.Lock()
+	job.Due = due
+	job.running = false
+	d.mu.Unlock()
+}
+
+// sendSink отправляет уведомление или сводку. Если получателя успели удалить из подписки,
+// отправлять нечего.
+func (d *Dispatcher) sendSink(ctx context.Context, job *sinkJob) error {
+	sink, ok := d.findSink(job.ChatID, job.Target)
+	if !ok {
+		return nil
+	}
+
+	notifier, err := NewNotifier(sink, d.sinkClient)
+	if err != nil {
+		log.Printf("Dropping notification for invalid sink of chat %d: %v", job.ChatID, err)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sinkTimeout)
+	defer cancel()
+
+	if job.Digest != "" {
+		return notifier.NotifyDigest(ctx, digestAccounts(job.Items), job.Digest, job.Items)
+	}
+	return notifier.Notify(ctx, job.Items[0])
+}
+
+// findSink ищет получателя по models.Sink.ID среди текущих получателей чата.
+func (d *Dispatcher) findSink(chatID int64, id string) (models.Sink, bool) {
+	d.mu.Lock()
+	chatID = d.currentChat(chatID)
+	d.mu.Unlock()
+
+	config, _ := d.telegramBot.GetConfig(chatID)
+	for _, sink := range config.Sinks {
+		if sink.ID() == id {
+			return sink, true
+		}
+	}
+	return models.Sink{}, false
+}
+
+// sinkID возвращает идентификатор получателя из сохранённой записи. Раньше в записях
+// хранился сам адрес - он заменяется его хэшем.
+func sinkID(target string) string {
+	if strings.Contains(target, "://") {
+		return models.Sink{URL: target}.ID()
+	}
+	return target
+}
+
+func (d *Dispatcher) finishSink(job *sinkJob) {
+	d.mu.Lock()
+	for i, queued := range d.sinkJobs {
+		if queued == job {
+			d.sinkJobs = append(d.sinkJobs[:i], d.sinkJobs[i+1:]...)
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	d.forget([]int64{job.ID})
+}
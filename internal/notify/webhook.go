@@ -0,0 +1,283 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// Лимиты Discord на заголовок и описание embed.
+const (
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+)
+
+// SlackNotifier отправляет уведомления во входящий webhook Slack.
+type SlackNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	sum := summarize(n)
+
+	title := slackEscape(sum.Title)
+	if sum.URL != "" {
+		title = "<" + sum.URL + "|" + title + ">"
+	}
+	text := "*" + title + "*"
+	if sum.Text != "" {
+		text += "\n" + slackEscape(sum.Text)
+	}
+
+	return postJSON(ctx, s.Client, s.URL, map[string]string{"text": text})
+}
+
+func (s *SlackNotifier) NotifyDigest(ctx context.Context, title, mode string, items []Notification) error {
+	lines := []string{"*" + slackEscape(digestHeading(title, mode)) + "*"}
+	for _, item := range items {
+		sum := summarize(item)
+		line := slackEscape(sum.Title)
+		if sum.URL != "" {
+			line = "<" + sum.URL + "|" + line + ">"
+		}
+		lines = append(lines, "• "+line)
+	}
+
+	return postJSON(ctx, s.Client, s.URL, map[string]string{"text": strings.Join(lines, "\n")})
+}
+
+// DiscordNotifier отправляет уведомления в webhook канала Discord.
+type DiscordNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Timestamp   string `json:"timestamp,omitempty"`
+}
+
+func (d *DiscordNotifier) Notify(ctx context.Context, n Notification) error {
+	sum := summarize(n)
+
+	embed := discordEmbed{
+		Title:       truncate(sum.Title, discordTitleLimit),
+		Description: truncate(sum.Text, discordDescriptionLimit),
+		URL:         sum.URL,
+	}
+	if !n.Time.IsZero() {
+		embed.Timestamp = n.Time.UTC().Format(time.RFC3339)
+	}
+
+	return postJSON(ctx, d.Client, d.URL, map[string][]discordEmbed{"embeds": {embed}})
+}
+
+func (d *DiscordNotifier) NotifyDigest(ctx context.Context, title, mode string, items []Notification) error {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		sum := summarize(item)
+		line := sum.Title
+		if sum.URL != "" {
+			line = "[" + sum.Title + "](" + sum.URL + ")"
+		}
+		lines = append(lines, "• "+line)
+	}
+
+	embed := discordEmbed{
+		Title:       truncate(digestHeading(title, mode), discordTitleLimit),
+		Description: truncate(strings.Join(lines, "\n"), discordDescriptionLimit),
+	}
+	return postJSON(ctx, d.Client, d.URL, map[string][]discordEmbed{"embeds": {embed}})
+}
+
+// WebhookNotifier отправляет событие целиком в JSON на произвольный адрес.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	Type        models.EventType        `json:"type"`
+	Account     string                  `json:"account"`
+	Repo        string                  `json:"repo"`
+	Time        time.Time               `json:"time"`
+	Alert       string                  `json:"alert,omitempty"`
+	Title       string                  `json:"title"`
+	Text        string                  `json:"text,omitempty"`
+	URL         string                  `json:"url,omitempty"`
+	Repository  *models.Repository      `json:"repository,omitempty"`
+	Commit      *models.Commit          `json:"commit,omitempty"`
+	Release     *models.Release         `json:"release,omitempty"`
+	PullRequest *models.PullRequest     `json:"pull_request,omitempty"`
+	Action      string                  `json:"action,omitempty"` // для pull_request: opened, merged или closed
+	CI          *models.CIStatus        `json:"ci,omitempty"`
+	Manifests   []models.ManifestChange `json:"manifests,omitempty"`
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.Client, w.URL, newWebhookPayload(n))
+}
+
+// webhookDigest - сводка для webhook: заголовок и события в том же формате, что и по отдельности.
+type webhookDigest struct {
+	Type   string           `json:"type"` // всегда "digest"
+	Title  string           `json:"title"`
+	Mode   string           `json:"mode"`
+	Events []webhookPayload `json:"events"`
+}
+
+func (w *WebhookNotifier) NotifyDigest(ctx context.Context, title, mode string, items []Notification) error {
+	digest := webhookDigest{Type: "digest", Title: digestHeading(title, mode), Mode: mode}
+	for _, item := range items {
+		digest.Events = append(digest.Events, newWebhookPayload(item))
+	}
+	return postJSON(ctx, w.Client, w.URL, digest)
+}
+
+func newWebhookPayload(n Notification) webhookPayload {
+	sum := summarize(n)
+	payload := webhookPayload{
+		Type:        n.Type,
+		Account:     n.Account,
+		Repo:        n.Repo,
+		Time:        n.Time,
+		Alert:       n.Alert,
+		Title:       sum.Title,
+		Text:        sum.Text,
+		URL:         sum.URL,
+		Repository:  n.Repository,
+		Release:     n.Release,
+		PullRequest: n.PullRequest,
+		Action:      n.Action,
+		CI:          n.CI,
+		Manifests:   n.Manifests,
+	}
+	if n.Commit != nil {
+		// Диффы могут быть большими и получателю не нужны.
+		commit := *n.Commit
+		commit.Files = nil
+		payload.Commit = &commit
+	}
+	return payload
+}
+
+// digestHeading - заголовок сводки без разметки.
+func digestHeading(title, mode string) string {
+	return "📋 Сводка " + digestPeriod(mode) + ": " + title
+}
+
+func postJSON(ctx context.Context, client *http.Client, endpoint string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// url.Error содержит адрес вместе с токеном webhook, наружу отдаётся только причина.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// summary - описание события без разметки Telegram для внешних получателей.
+type summary struct {
+	Title string
+	Text  string
+	URL   string
+}
+
+func summarize(n Notification) summary {
+	var sum summary
+	target := n.Account + "/" + n.Repo
+
+	switch n.Type {
+	case models.EventNewRepo:
+		sum = summary{Title: "🆕 Новый репозиторий " + target, Text: n.Repository.Description, URL: n.Repository.URL}
+
+	case models.EventCommit:
+		commit := n.Commit
+		sum = summary{Title: "📝 Новый коммит в " + target, Text: commit.Message + "\n\nАвтор: " + commit.Author, URL: commit.URL}
+
+	case models.EventRelease:
+		release := n.Release
+		sum = summary{Title: "🏷 Новый релиз " + target + " " + release.TagName, Text: release.Name, URL: release.URL}
+		if release.Name == release.TagName {
+			sum.Text = ""
+		}
+
+	case models.EventPullRequest:
+		pull := n.PullRequest
+		sum = summary{
+			Title: pullRequestTitles[n.Action] + " в " + target + ": #" + strconv.Itoa(pull.Number) + " " + pull.Title,
+			Text:  "Автор: " + pull.Author,
+			URL:   pull.URL,
+		}
+
+	case models.EventCI:
+		ci := n.CI
+		text := "Коммит: " + ci.Message + " (" + ci.Author + ")"
+		if len(ci.Failed) > 0 {
+			text += "\nУпали: " + strings.Join(ci.Failed, ", ")
+		}
+		sum = summary{Title: ciTitle(ci) + " в " + target, Text: text, URL: ci.URL}
+
+	case models.EventDependency:
+		var lines []string
+		for _, manifest := range n.Manifests {
+			lines = append(lines, manifest.File+" ("+manifest.Ecosystem+"):")
+			for _, change := range manifest.Changes {
+				lines = append(lines, describeChange(change))
+			}
+		}
+		lines = append(lines, "", "Коммит: "+strings.SplitN(n.Commit.Message, "\n", 2)[0]+" ("+n.Commit.Author+")")
+		sum = summary{Title: "📦 Изменены зависимости в " + target, Text: strings.Join(lines, "\n"), URL: n.Commit.URL}
+	}
+
+	if n.Alert != "" {
+		sum.Title = "🚨 ВАЖНО: " + sum.Title
+	}
+	return sum
+}
+
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,223 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// sinkServer записывает тела запросов и отвечает статусом status.
+func sinkServer(t *testing.T, status int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var bodies []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("response body"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func commitNotification() Notification {
+	return Notification{
+		Type:    models.EventCommit,
+		Account: "octocat",
+		Repo:    "hello",
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Commit: &models.Commit{
+			SHA:     "abc1234",
+			Message: "fix: <escape> & done\n\nbody",
+			Author:  "octocat",
+			URL:     "https://github.com/octocat/hello/commit/abc1234",
+			Files:   []models.CommitFile{{Filename: "main.go", Patch: "+secret diff"}},
+		},
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, bodies := sinkServer(t, http.StatusOK)
+	notifier := &SlackNotifier{URL: server.URL + "/services/T0/B0/token", Client: server.Client()}
+
+	if err := notifier.Notify(context.Background(), commitNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	text := payload["text"]
+	if !strings.Contains(text, "<https://github.com/octocat/hello/commit/abc1234|") {
+		t.Errorf("text has no link: %q", text)
+	}
+	if !strings.Contains(text, "fix: &lt;escape&gt; &amp; done") {
+		t.Errorf("text is not escaped: %q", text)
+	}
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	server, bodies := sinkServer(t, http.StatusNoContent)
+	notifier := &DiscordNotifier{URL: server.URL + "/api/webhooks/1/token", Client: server.Client()}
+
+	if err := notifier.Notify(context.Background(), commitNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var payload struct {
+		Embeds []discordEmbed `json:"embeds"`
+	}
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(payload.Embeds))
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "📝 Новый коммит в octocat/hello" || embed.URL != commitNotification().Commit.URL {
+		t.Errorf("unexpected embed %+v", embed)
+	}
+	if embed.Timestamp != "2024-05-01T12:00:00Z" {
+		t.Errorf("timestamp = %q", embed.Timestamp)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server, bodies := sinkServer(t, http.StatusAccepted)
+	notifier := &WebhookNotifier{URL: server.URL + "/hook", Client: server.Client()}
+
+	if err := notifier.Notify(context.Background(), commitNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if payload.Type != models.EventCommit || payload.Account != "octocat" || payload.Repo != "hello" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+	if payload.Commit == nil || payload.Commit.SHA != "abc1234" {
+		t.Fatalf("commit missing: %+v", payload.Commit)
+	}
+	if strings.Contains((*bodies)[0], "secret diff") {
+		t.Error("payload contains commit diffs")
+	}
+}
+
+func TestWebhookNotifierDigest(t *testing.T) {
+	server, bodies := sinkServer(t, http.StatusOK)
+	notifier := &WebhookNotifier{URL: server.URL + "/hook", Client: server.Client()}
+
+	items := []Notification{commitNotification(), commitNotification()}
+	if err := notifier.NotifyDigest(context.Background(), "octocat", models.DigestDaily, items); err != nil {
+		t.Fatalf("NotifyDigest: %v", err)
+	}
+
+	var digest webhookDigest
+	if err := json.Unmarshal([]byte((*bodies)[0]), &digest); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if digest.Type != "digest" || digest.Mode != models.DigestDaily || len(digest.Events) != 2 {
+		t.Errorf("unexpected digest %+v", digest)
+	}
+}
+
+func TestNotifierErrorStatus(t *testing.T) {
+	server, _ := sinkServer(t, http.StatusInternalServerError)
+	notifier := &SlackNotifier{URL: server.URL + "/services/T0/B0/token", Client: server.Client()}
+
+	err := notifier.Notify(context.Background(), commitNotification())
+	if err == nil {
+		t.Fatal("expected an error for 500 response")
+	}
+	if !strings.Contains(err.Error(), "500") {
+		t.Errorf("error has no status: %v", err)
+	}
+}
+
+func TestNotifierErrorHidesURL(t *testing.T) {
+	server, _ := sinkServer(t, http.StatusOK)
+	notifier := &SlackNotifier{URL: server.URL + "/services/T0/B0/token", Client: server.Client()}
+	server.Close()
+
+	err := notifier.Notify(context.Background(), commitNotification())
+	if err == nil {
+		t.Fatal("expected an error for closed server")
+	}
+	if strings.Contains(err.Error(), "token") || strings.Contains(err.Error(), "/services/") {
+		t.Errorf("error leaks webhook URL: %v", err)
+	}
+}
+
+func TestSinkClientBlocksLoopback(t *testing.T) {
+	called := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{URL: server.URL + "/hook", Client: NewSinkClient(time.Second)}
+	err := notifier.Notify(context.Background(), commitNotification())
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected loopback to be rejected, got %v", err)
+	}
+	if called {
+		t.Error("request reached the loopback server")
+	}
+}
+
+func TestSinkClientIgnoresRedirects(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	client.CheckRedirect = NewSinkClient(time.Second).CheckRedirect
+
+	notifier := &WebhookNotifier{URL: server.URL + "/hook", Client: client}
+	err := notifier.Notify(context.Background(), commitNotification())
+	if err == nil || !strings.Contains(err.Error(), "302") {
+		t.Errorf("expected redirect to be reported as an error, got %v", err)
+	}
+}
+
+func TestSinkValidate(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.slack.com/services/T0/B0/token", true},
+		{"https://93.184.216.34/hook", true},
+		{"http://hooks.slack.com/services/T0/B0/token", false},
+		{"https://localhost/hook", false},
+		{"https://api.localhost/hook", false},
+		{"https://127.0.0.1:8080/hook", false},
+		{"https://10.0.0.5/hook", false},
+		{"https://192.168.1.1/hook", false},
+		{"https://169.254.169.254/latest/meta-data/", false},
+		{"https://100.64.0.1/hook", false},
+		{"https://[::1]/hook", false},
+		{"https://[fe80::1]/hook", false},
+		{"https://[::ffff:127.0.0.1]/hook", false},
+		{"ftp://example.com/hook", false},
+	}
+	for _, tt := range tests {
+		err := models.Sink{Kind: models.SinkWebhook, URL: tt.url}.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("Validate(%q) = %v, want ok=%v", tt.url, err, tt.ok)
+		}
+	}
+}
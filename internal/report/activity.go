@@ -0,0 +1,72 @@
+package report
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/github"
+)
+
+// Сколько самых свежих репозиториев учитывается в разбивке по языкам: по запросу на каждый.
+const maxLanguageRepos = 30
+
+// CommitsByDay считает коммиты аккаунта по дням (2006-01-02 в часовом поясе loc) за период.
+func CommitsByDay(ctx context.Context, client *github.Client, account string, since, until time.Time, loc *time.Location) (map[string]int, error) {
+	repos, err := client.GetRepositories(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]int)
+	for _, repo := range repos {
+		if repo.PushedAt.Before(since) {
+			continue
+		}
+
+		commits, err := client.ListCommits(ctx, account, repo.Name, since, until, maxCommitsPerRepo)
+		if err != nil {
+			log.Printf("Failed to list commits of %s/%s for chart: %v", account, repo.Name, err)
+			continue
+		}
+		for _, commit := range commits {
+			days[commit.Date.In(loc).Format("2006-01-02")]++
+		}
+	}
+
+	return days, nil
+}
+
+// Languages суммирует объём кода по языкам в собственных (не форкнутых) репозиториях аккаунта.
+func Languages(ctx context.Context, client *github.Client, account string) (map[string]int, error) {
+	repos, err := client.GetRepositories(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(repos, func(i, j int) bool { return repos[i].PushedAt.After(repos[j].PushedAt) })
+
+	languages := make(map[string]int)
+	checked := 0
+	for _, repo := range repos {
+		if repo.Fork {
+			continue
+		}
+		if checked == maxLanguageRepos {
+			break
+		}
+		checked++
+
+		repoLanguages, err := client.GetLanguages(ctx, account, repo.Name)
+		if err != nil {
+			log.Printf("Failed to get languages of %s/%s: %v", account, repo.Name, err)
+			continue
+		}
+		for language, size := range repoLanguages {
+			languages[language] += size
+		}
+	}
+
+	return languages, nil
+}
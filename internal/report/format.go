@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const topRepos = 3
+
+// Format собирает отчёт в HTML; даты показываются в часовом поясе чата.
+func Format(r Report, config models.MonitoringConfig) string {
+	cur, prev := r.Current, r.Previous
+	loc := config.Location()
+
+	var b strings.Builder
+	b.WriteString("📊 <b>Еженедельный отчёт: " + html.EscapeString(r.Account) + "</b>\n")
+	b.WriteString(cur.From.In(loc).Format("02.01") + " - " + cur.To.In(loc).Format("02.01.2006") + "\n\n")
+
+	b.WriteString("📝 Коммиты: " + fmt.Sprint(cur.Commits) + compare(cur.Commits, prev.Commits) + "\n")
+
+	lines := fmt.Sprintf("+%d / −%d", cur.Additions, cur.Deletions)
+	if cur.LinesPartial {
+		lines += " (не менее, посчитаны не все коммиты)"
+	}
+	b.WriteString("✏️ Строки: " + lines + compare(cur.Additions+cur.Deletions, prev.Additions+prev.Deletions) + "\n")
+
+	if cur.MergedPRs >= 0 {
+		prevPRs := prev.MergedPRs
+		if prevPRs < 0 {
+			prevPRs = cur.MergedPRs
+		}
+		b.WriteString("🔀 Влито PR: " + fmt.Sprint(cur.MergedPRs) + compare(cur.MergedPRs, prevPRs) + "\n")
+	}
+	b.WriteString("🏷 Релизы: " + fmt.Sprint(len(cur.Releases)) + compare(len(cur.Releases), len(prev.Releases)) + "\n")
+	b.WriteString("🆕 Новые репозитории: " + fmt.Sprint(len(cur.NewRepos)) + compare(len(cur.NewRepos), len(prev.NewRepos)) + "\n")
+
+	if len(cur.ByRepo) > 0 {
+		b.WriteString("\n<b>Коммиты по репозиториям:</b>\n")
+		medals := []string{"🥇", "🥈", "🥉"}
+		for i, repo := range sortedRepos(cur.ByRepo) {
+			marker := "•"
+			if i < topRepos {
+				marker = medals[i]
+			}
+			b.WriteString(fmt.Sprintf("%s %s - %d%s\n", marker, html.EscapeString(repo), cur.ByRepo[repo],
+				compare(cur.ByRepo[repo], prev.ByRepo[repo])))
+		}
+	}
+
+	if len(cur.Releases) > 0 {
+		b.WriteString("\n<b>Релизы:</b>\n")
+		for _, item := range cur.Releases {
+			name := item.Release.Name
+			if name == "" {
+				name = item.Release.TagName
+			}
+			b.WriteString("• " + html.EscapeString(item.Repo) + `: <a href="` + html.EscapeString(item.Release.URL) + `">` +
+				html.EscapeString(name) + "</a>\n")
+		}
+	}
+
+	if len(cur.NewRepos) > 0 {
+		b.WriteString("\n<b>Новые репозитории:</b>\n")
+		for _, repo := range cur.NewRepos {
+			b.WriteString(`• <a href="` + html.EscapeString(repo.URL) + `">` + html.EscapeString(repo.Name) + "</a>\n")
+		}
+	}
+
+	if cur.Commits == 0 && len(cur.Releases) == 0 && len(cur.NewRepos) == 0 && cur.MergedPRs <= 0 {
+		b.WriteString("\nЗа неделю активности не было.\n")
+	}
+
+	return b.String()
+}
+
+// compare показывает изменение относительно прошлой недели.
+func compare(current, previous int) string {
+	switch {
+	case current > previous:
+		return fmt.Sprintf(" (▲ %d к прошлой неделе)", current-previous)
+	case current < previous:
+		return fmt.Sprintf(" (▼ %d к прошлой неделе)", previous-current)
+	}
+	return ""
+}
+
+func sortedRepos(byRepo map[string]int) []string {
+	repos := make([]string, 0, len(byRepo))
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if byRepo[repos[i]] != byRepo[repos[j]] {
+			return byRepo[repos[i]] > byRepo[repos[j]]
+		}
+		return repos[i] < repos[j]
+	})
+	return repos
+}
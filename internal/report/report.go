@@ -0,0 +1,138 @@
+package report
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/github"
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const (
+	week = 7 * 24 * time.Hour
+
+	// Ограничения на число запросов к GitHub для одного отчёта.
+	maxCommitsPerRepo  = 500
+	maxReleasesPerRepo = 30
+	maxDetailedCommits = 100 // коммитов за неделю, для которых загружается статистика строк
+)
+
+type RepoRelease struct {
+	Repo    string
+	Release models.Release
+}
+
+// Week - активность аккаунта за семь дней [From, To).
+type Week struct {
+	From, To     time.Time
+	Commits      int
+	CommitTimes  []time.Time
+	ByRepo       map[string]int
+	NewRepos     []models.Repository
+	Releases     []RepoRelease
+	MergedPRs    int // -1, если поиск GitHub недоступен
+	Additions    int
+	Deletions    int
+	LinesPartial bool // строки посчитаны не по всем коммитам
+}
+
+type Report struct {
+	Account  string
+	Current  Week
+	Previous Week
+}
+
+// Collect собирает отчёт за неделю, заканчивающуюся в end, и за неделю до неё.
+func Collect(ctx context.Context, client *github.Client, account string, end time.Time) (Report, error) {
+	repos, err := client.GetRepositories(ctx, account)
+	if err != nil {
+		return Report{}, err
+	}
+
+	result := Report{
+		Account:  account,
+		Current:  newWeek(end.Add(-week), end),
+		Previous: newWeek(end.Add(-2*week), end.Add(-week)),
+	}
+	weeks := []*Week{&result.Current, &result.Previous}
+
+	for _, repo := range repos {
+		for _, w := range weeks {
+			if w.contains(repo.CreatedAt) {
+				w.NewRepos = append(w.NewRepos, repo)
+			}
+		}
+
+		// Репозитории без пушей за две недели не могут содержать новых коммитов и релизов.
+		if repo.PushedAt.Before(result.Previous.From) {
+			continue
+		}
+
+		commits, err := client.ListCommits(ctx, account, repo.Name, result.Previous.From, end, maxCommitsPerRepo)
+		if err != nil {
+			log.Printf("Failed to list commits of %s/%s for report: %v", account, repo.Name, err)
+		}
+		for _, commit := range commits {
+			for _, w := range weeks {
+				if w.contains(commit.Date) {
+					w.addCommit(ctx, client, account, repo.Name, commit)
+				}
+			}
+		}
+
+		releases, err := client.GetReleases(ctx, account, repo.Name, maxReleasesPerRepo)
+		if err != nil {
+			log.Printf("Failed to list releases of %s/%s for report: %v", account, repo.Name, err)
+		}
+		for _, release := range releases {
+			if release.Draft {
+				continue
+			}
+			for _, w := range weeks {
+				if w.contains(release.PublishedAt) {
+					w.Releases = append(w.Releases, RepoRelease{Repo: repo.Name, Release: release})
+				}
+			}
+		}
+	}
+
+	for _, w := range weeks {
+		merged, err := client.CountMergedPullRequests(ctx, account, w.From, w.To)
+		if err != nil {
+			log.Printf("Failed to count merged pull requests of %s for report: %v", account, err)
+			merged = -1
+		}
+		w.MergedPRs = merged
+	}
+
+	return result, nil
+}
+
+func newWeek(from, to time.Time) Week {
+	return Week{From: from, To: to, ByRepo: make(map[string]int)}
+}
+
+func (w *Week) contains(t time.Time) bool {
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+func (w *Week) addCommit(ctx context.Context, client *github.Client, account, repo string, commit models.Commit) {
+	w.Commits++
+	w.CommitTimes = append(w.CommitTimes, commit.Date)
+	w.ByRepo[repo]++
+
+	if w.Commits > maxDetailedCommits {
+		w.LinesPartial = true
+		return
+	}
+
+	detailed, err := client.GetCommit(ctx, account, repo, commit.SHA)
+	if err != nil {
+		log.Printf("Failed to get stats of commit %s in %s/%s: %v", commit.SHA, account, repo, err)
+		w.LinesPartial = true
+		return
+	}
+	w.Additions += detailed.Additions
+	w.Deletions += detailed.Deletions
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const (
+	DefaultDay = "mon"
+	DefaultAt  = "09:00"
+)
+
+var Weekdays = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+// LastScheduled возвращает последний момент отправки отчёта по расписанию чата, не позже now.
+func LastScheduled(config models.MonitoringConfig, now time.Time) time.Time {
+	day, ok := Weekdays[config.Report.Day]
+	if !ok {
+		day = time.Monday
+	}
+	at, err := time.Parse("15:04", config.Report.At)
+	if err != nil {
+		at, _ = time.Parse("15:04", DefaultAt)
+	}
+
+	now = now.In(config.Location())
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	scheduled = scheduled.AddDate(0, 0, -int((now.Weekday()-day+7)%7))
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -7)
+	}
+	return scheduled
+}
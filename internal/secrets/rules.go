@@ -0,0 +1,67 @@
+package secrets
+
+import "regexp"
+
+// Rule описывает один тип секрета. Если в выражении есть группа (?P<secret>...),
+// маскируется и проверяется на энтропию только она, иначе строка выводится как есть.
+type Rule struct {
+	ID          string
+	Description string
+	Regexp      *regexp.Regexp
+	MinEntropy  float64 // 0 - не проверять энтропию
+}
+
+// Rules - набор правил, по которому проверяются коммиты.
+// Новые правила достаточно добавить в этот список.
+var Rules = []Rule{
+	{
+		ID:          "private-key",
+		Description: "Приватный ключ",
+		Regexp:      regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "aws-access-key",
+		Description: "AWS Access Key ID",
+		Regexp:      regexp.MustCompile(`\b(?P<secret>(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16})\b`),
+	},
+	{
+		ID:          "aws-secret-key",
+		Description: "AWS Secret Access Key",
+		Regexp:      regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?['"](?P<secret>[0-9a-zA-Z/+]{40})['"]`),
+	},
+	{
+		ID:          "github-token",
+		Description: "Токен GitHub",
+		Regexp:      regexp.MustCompile(`\b(?P<secret>(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{60,255}))\b`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Токен Slack",
+		Regexp:      regexp.MustCompile(`\b(?P<secret>xox[abposr]-[0-9A-Za-z-]{10,})\b`),
+	},
+	{
+		ID:          "high-entropy",
+		Description: "Похожая на секрет строка с высокой энтропией",
+		Regexp: regexp.MustCompile(`(?i)(?:key|secret|token|passw(?:or)?d|pwd|credential)[\w.-]*["']?\s*[:=]\s*["']?` +
+			`(?P<secret>[A-Za-z0-9+/=_\-]{20,})`),
+		MinEntropy: 3.7,
+	},
+}
+
+// RuleIDs возвращает идентификаторы правил для подсказок в командах.
+func RuleIDs() []string {
+	ids := make([]string, 0, len(Rules))
+	for _, rule := range Rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func HasRule(id string) bool {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/filter"
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const (
+	maxFindings   = 10
+	maxSnippetLen = 120
+)
+
+// Scan проверяет добавленные строки диффов и возвращает найденные секреты.
+// На каждую строку приходится не больше одной находки - по первому совпавшему правилу.
+func Scan(files []models.CommitFile, rules []Rule) []models.SecretFinding {
+	var findings []models.SecretFinding
+
+	for _, file := range files {
+		for _, line := range addedLines(file.Patch) {
+			finding, ok := scanLine(line.text, rules)
+			if !ok {
+				continue
+			}
+			finding.File = file.Filename
+			finding.Line = line.number
+			findings = append(findings, finding)
+
+			if len(findings) >= maxFindings {
+				return findings
+			}
+		}
+	}
+
+	return findings
+}
+
+// Suppressed сообщает, подавлена ли находка правилами /secrets ignore.
+func Suppressed(ignores []models.SecretIgnore, repo string, finding models.SecretFinding) bool {
+	for _, ignore := range ignores {
+		if ignore.Repo != "*" && !strings.EqualFold(ignore.Repo, repo) {
+			continue
+		}
+		if ignore.Rule != "" && ignore.Rule != finding.Rule {
+			continue
+		}
+		if ignore.Path != "" && !filter.MatchPath(ignore.Path, finding.File) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// scanLine находит первое правило с подходящим совпадением. Во фрагменте строки маскируются
+// все совпадения всех правил, включая отброшенные по энтропии, чтобы наружу не ушла часть секрета.
+func scanLine(text string, rules []Rule) (models.SecretFinding, bool) {
+	var (
+		found   *Rule
+		secrets [][2]int
+	)
+
+	for i := range rules {
+		rule := &rules[i]
+		group := rule.Regexp.SubexpIndex("secret")
+
+		for _, match := range rule.Regexp.FindAllStringSubmatchIndex(text, -1) {
+			if group <= 0 || match[2*group] < 0 {
+				if found == nil {
+					found = rule
+				}
+				continue
+			}
+
+			start, end := match[2*group], match[2*group+1]
+			secrets = append(secrets, [2]int{start, end})
+			if found == nil && (rule.MinEntropy == 0 || entropy(text[start:end]) >= rule.MinEntropy) {
+				found = rule
+			}
+		}
+	}
+	if found == nil {
+		return models.SecretFinding{}, false
+	}
+
+	return models.SecretFinding{
+		Rule:        found.ID,
+		Description: found.Description,
+		Snippet:     truncate(strings.TrimSpace(redactAll(text, secrets)), maxSnippetLen),
+	}, true
+}
+
+// redactAll маскирует в строке указанные участки, пересекающиеся участки объединяются.
+func redactAll(text string, spans [][2]int) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(spans); i++ {
+		start, end := spans[i][0], spans[i][1]
+		for i+1 < len(spans) && spans[i+1][0] < end {
+			i++
+			end = max(end, spans[i][1])
+		}
+		if start < pos {
+			start = pos
+		}
+		if start >= end {
+			continue
+		}
+		b.WriteString(text[pos:start])
+		b.WriteString(Redact(text[start:end]))
+		pos = end
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// Redact оставляет от секрета первые четыре символа.
+func Redact(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8)
+}
+
+type patchLine struct {
+	number int
+	text   string
+}
+
+// addedLines разбирает unified diff и возвращает добавленные строки с номерами в новой версии файла.
+func addedLines(patch string) []patchLine {
+	var lines []patchLine
+	number := 0
+
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			number = hunkStart(line)
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, patchLine{number: number, text: line[1:]})
+			number++
+		case strings.HasPrefix(line, "-"), strings.HasPrefix(line, `\`):
+		default:
+			number++
+		}
+	}
+
+	return lines
+}
+
+// hunkStart извлекает начальную строку новой версии из заголовка "@@ -a,b +c,d @@".
+func hunkStart(header string) int {
+	i := strings.Index(header, "+")
+	if i == -1 {
+		return 0
+	}
+	rest := header[i+1:]
+	if end := strings.IndexAny(rest, ", "); end != -1 {
+		rest = rest[:end]
+	}
+	start, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0
+	}
+	return start
+}
+
+// entropy - энтропия Шеннона в битах на символ.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var result float64
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		result -= p * math.Log2(p)
+	}
+	return result
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
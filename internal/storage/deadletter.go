@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// DeadLetterStore хранит сообщения, которые не удалось доставить, до разбора администратором.
+type DeadLetterStore interface {
+	AddDeadLetter(ctx context.Context, letter models.DeadLetter) error
+	// ListDeadLetters возвращает ещё не переотправленные сообщения, новые первыми, без вложенных графиков.
+	ListDeadLetters(ctx context.Context, limit int) ([]models.DeadLetter, error)
+	// GetDeadLetter возвращает сообщение целиком или nil, если его нет.
+	GetDeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error)
+	// ClaimDeadLetter атомарно отмечает сообщение переотправленным и возвращает его. Если сообщения нет
+	// или его уже забрал другой вызов, возвращается nil: одно сообщение не переотправляется дважды.
+	ClaimDeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error)
+	// ReleaseDeadLetter снимает отметку, если переотправить сообщение не удалось.
+	ReleaseDeadLetter(ctx context.Context, id int64) error
+	DeleteDeadLetter(ctx context.Context, id int64) error
+	// MoveDeadLetters переносит сообщения чата на новый ID после преобразования группы в супергруппу.
+	MoveDeadLetters(ctx context.Context, oldChatID, newChatID int64) error
+}
+
+// DeliveryStore - всё, что нужно доставке уведомлений: outbox событий, отложенные
+// уведомления и очередь недоставленных сообщений.
+type DeliveryStore interface {
+	EventStore
+	PendingStore
+	DeadLetterStore
+}
@@ -0,0 +1,385 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+// MemoryStore хранит копии настроек и событий в JSON, чтобы вести себя так же, как Postgres.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	configs  map[int64][]byte
+	accounts map[string][]byte
+	outbox   []*memoryOutboxEvent
+	nextID   int64
+
+	pending       []PendingNotification
+	nextPendingID int64
+
+	deadLetters  []models.DeadLetter
+	nextLetterID int64
+}
+
+type memoryOutboxEvent struct {
+	id          int64
+	kind        models.EventType
+	payload     []byte
+	status      string
+	attempts    int
+	nextAttempt time.Time
+	lastError   string
+	deliveredAt time.Time
+	recipients  []string // кому событие уже доставлено
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		configs:  make(map[int64][]byte),
+		accounts: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStore) LoadConfigs(ctx context.Context) (map[int64]*models.MonitoringConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	configs := make(map[int64]*models.MonitoringConfig, len(s.configs))
+	for chatID, data := range s.configs {
+		var config models.MonitoringConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, err
+		}
+		configs[chatID] = &config
+	}
+	return configs, nil
+}
+
+func (s *MemoryStore) SaveConfig(ctx context.Context, chatID int64, config *models.MonitoringConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.configs[chatID] = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) DeleteConfig(ctx context.Context, chatID int64) error {
+	s.mu.Lock()
+	delete(s.configs, chatID)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) LoadAccount(ctx context.Context, account string) (*models.AccountSnapshot, error) {
+	s.mu.RLock()
+	data, exists := s.accounts[strings.ToLower(account)]
+	s.mu.RUnlock()
+
+	if !exists {
+		return nil, nil
+	}
+	var snapshot models.AccountSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (s *MemoryStore) SavePoll(ctx context.Context, account string, snapshot models.AccountSnapshot, events []models.Event) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	payloads := make([][]byte, len(events))
+	for i, event := range events {
+		if payloads[i], err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts[strings.ToLower(account)] = data
+	for i, event := range events {
+		s.nextID++
+		s.outbox = append(s.outbox, &memoryOutboxEvent{
+			id:          s.nextID,
+			kind:        event.Kind(),
+			payload:     payloads[i],
+			status:      outboxPending,
+			nextAttempt: time.Now(),
+		})
+	}
+	return nil
+}
+
+func (s *MemoryStore) PendingEvents(ctx context.Context, now time.Time, limit int) ([]OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []OutboxEvent
+	for _, record := range s.outbox {
+		if len(result) == limit {
+			break
+		}
+		if record.status != outboxPending || record.nextAttempt.After(now) {
+			continue
+		}
+		event, err := models.DecodeEvent(record.kind, record.payload)
+		if err != nil {
+			// Повтор не поможет: событие откладывается, чтобы не блокировать остальные.
+			log.Printf("Unable to decode outbox event %d: %v", record.id, err)
+			record.status = outboxFailed
+			record.lastError = err.Error()
+			continue
+		}
+		result = append(result, OutboxEvent{ID: record.id, Event: event, Attempts: record.attempts})
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) MarkDelivered(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record := s.findEvent(id); record != nil {
+		record.status = outboxDelivered
+		record.deliveredAt = time.Now()
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeliveredTo(ctx context.Context, id int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if record := s.findEvent(id); record != nil {
+		return append([]string(nil), record.recipients...), nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) MarkDeliveredTo(ctx context.Context, id int64, recipient string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record := s.findEvent(id); record != nil {
+		record.recipients = append(record.recipients, recipient)
+	}
+	return nil
+}
+
+func (s *MemoryStore) MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record := s.findEvent(id); record != nil {
+		record.attempts++
+		record.lastError = reason
+		record.nextAttempt = retryAt
+		if retryAt.IsZero() {
+			record.status = outboxFailed
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) RetryEvent(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record := s.findEvent(id); record != nil && record.status == outboxFailed {
+		record.status = outboxPending
+		record.attempts = 0
+		record.nextAttempt = time.Now()
+	}
+	return nil
+}
+
+func (s *MemoryStore) PruneDelivered(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.outbox[:0]
+	for _, record := range s.outbox {
+		if record.status != outboxDelivered || !record.deliveredAt.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	s.outbox = kept
+	return nil
+}
+
+// findEvent ищет событие outbox; вызывается под s.mu.
+func (s *MemoryStore) findEvent(id int64) *memoryOutboxEvent {
+	for _, record := range s.outbox {
+		if record.id == id {
+			return record
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) AddPending(ctx context.Context, item PendingNotification) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextPendingID++
+	item.ID = s.nextPendingID
+	item.Payload = append([]byte(nil), item.Payload...)
+	s.pending = append(s.pending, item)
+	return item.ID, nil
+}
+
+func (s *MemoryStore) LoadPending(ctx context.Context) ([]PendingNotification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]PendingNotification(nil), s.pending...), nil
+}
+
+func (s *MemoryStore) DeletePending(ctx context.Context, ids []int64) error {
+	remove := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.pending[:0]
+	for _, item := range s.pending {
+		if !remove[item.ID] {
+			kept = append(kept, item)
+		}
+	}
+	s.pending = kept
+	return nil
+}
+
+func (s *MemoryStore) ReschedulePending(ctx context.Context, id int64, due time.Time, attempts int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.pending {
+		if s.pending[i].ID == id {
+			s.pending[i].Due = due
+			s.pending[i].Attempts = attempts
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) MovePending(ctx context.Context, oldChatID, newChatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.pending {
+		if s.pending[i].ChatID == oldChatID {
+			s.pending[i].ChatID = newChatID
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) AddDeadLetter(ctx context.Context, letter models.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextLetterID++
+	letter.ID = s.nextLetterID
+	s.deadLetters = append(s.deadLetters, letter)
+	return nil
+}
+
+func (s *MemoryStore) ListDeadLetters(ctx context.Context, limit int) ([]models.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []models.DeadLetter
+	for i := len(s.deadLetters) - 1; i >= 0 && len(result) < limit; i-- {
+		letter := s.deadLetters[i]
+		if letter.ReplayedAt != nil {
+			continue
+		}
+		letter.Photo = nil
+		result = append(result, letter)
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) GetDeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, letter := range s.deadLetters {
+		if letter.ID == id {
+			return &letter, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) ClaimDeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deadLetters {
+		if s.deadLetters[i].ID == id && s.deadLetters[i].ReplayedAt == nil {
+			now := time.Now()
+			s.deadLetters[i].ReplayedAt = &now
+			letter := s.deadLetters[i]
+			return &letter, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) ReleaseDeadLetter(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deadLetters {
+		if s.deadLetters[i].ID == id {
+			s.deadLetters[i].ReplayedAt = nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteDeadLetter(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, letter := range s.deadLetters {
+		if letter.ID == id {
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) MoveDeadLetters(ctx context.Context, oldChatID, newChatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deadLetters {
+		if s.deadLetters[i].ChatID == oldChatID {
+			s.deadLetters[i].ChatID = newChatID
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Close() {}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+)
+
+const (
+	outboxPending   = "pending"
+	outboxDelivered = "delivered"
+	outboxFailed    = "failed"
+)
+
+// OutboxEvent - найденное событие, ожидающее доставки.
+type OutboxEvent struct {
+	ID       int64
+	Event    models.Event
+	Attempts int // сколько попыток доставки уже не удалось
+}
+
+// EventStore хранит состояние опроса аккаунтов и outbox событий. Новое состояние аккаунта
+// и найденные события сохраняются одной транзакцией: если процесс упадёт до доставки,
+// события останутся в outbox и будут доставлены после перезапуска.
+type EventStore interface {
+	// LoadAccount возвращает сохранённое состояние аккаунта или nil, если его нет.
+	LoadAccount(ctx context.Context, account string) (*models.AccountSnapshot, error)
+	SavePoll(ctx context.Context, account string, snapshot models.AccountSnapshot, events []models.Event) error
+
+	// PendingEvents возвращает недоставленные события, время повтора которых наступило, в порядке обнаружения.
+	PendingEvents(ctx context.Context, now time.Time, limit int) ([]OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	// DeliveredTo возвращает получателей, которым событие уже доставлено: при повторе после частичной
+	// ошибки они его не получают снова.
+	DeliveredTo(ctx context.Context, id int64) ([]string, error)
+	MarkDeliveredTo(ctx context.Context, id int64, recipient string) error
+	// MarkFailed записывает неудачную попытку; при нулевом retryAt событие больше не доставляется.
+	MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error
+	// RetryEvent возвращает отложенное после всех попыток событие в очередь доставки.
+	RetryEvent(ctx context.Context, id int64) error
+	PruneDelivered(ctx context.Context, before time.Time) error
+}
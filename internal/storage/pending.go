@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Виды отложенных уведомлений.
+const (
+	PendingDigest      = "digest"       // событие, ожидающее сводки чата
+	PendingHeld        = "held"         // сообщение, придержанное до конца тихих часов или snooze
+	PendingSinkDigest  = "sink-digest"  // событие, ожидающее сводки внешнего получателя
+	PendingSink        = "sink"         // отправка внешнему получателю, ожидающая очереди или повтора
+	PendingEmailDigest = "email-digest" // событие, ожидающее почтовой сводки
+	PendingEmail       = "email"        // почтовая сводка, ожидающая отправки или повтора
+)
+
+// PendingNotification - уведомление, которое Dispatcher отложил на потом.
+// Payload хранилище не разбирает: его формат знает только Dispatcher.
+type PendingNotification struct {
+	ID       int64
+	Kind     string
+	ChatID   int64
+	Target   string    // адрес внешнего получателя или почты; для уведомлений в чат пусто
+	Due      time.Time // когда отправить; придержанные сообщения ждут конца тихих часов
+	Attempts int       // неудачные попытки отправки
+	Payload  []byte
+}
+
+// PendingStore хранит отложенные уведомления, чтобы накопленные сводки, придержанные
+// на тихие часы сообщения и неотправленные сообщения внешним получателям и письма пережили перезапуск.
+type PendingStore interface {
+	AddPending(ctx context.Context, item PendingNotification) (int64, error)
+	// LoadPending возвращает все отложенные уведомления в порядке добавления.
+	LoadPending(ctx context.Context) ([]PendingNotification, error)
+	DeletePending(ctx context.Context, ids []int64) error
+	// ReschedulePending переносит отправку после неудачной попытки.
+	ReschedulePending(ctx context.Context, id int64, due time.Time, attempts int) error
+	// MovePending переносит уведомления чата на новый ID после преобразования группы в супергруппу.
+	MovePending(ctx context.Context, oldChatID, newChatID int64) error
+}
@@ -0,0 +1,460 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/pkg/database"
+	"github.com/jackc/pgx/v5"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS chat_configs (
+	chat_id    BIGINT PRIMARY KEY,
+	config     JSONB NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS account_states (
+	account    TEXT PRIMARY KEY,
+	state      JSONB NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS event_outbox (
+	id           BIGSERIAL PRIMARY KEY,
+	account      TEXT NOT NULL,
+	kind         TEXT NOT NULL,
+	payload      JSONB NOT NULL,
+	status       TEXT NOT NULL DEFAULT 'pending',
+	attempts     INT NOT NULL DEFAULT 0,
+	next_attempt TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_error   TEXT NOT NULL DEFAULT '',
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS event_outbox_pending ON event_outbox (id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS event_deliveries (
+	event_id     BIGINT NOT NULL REFERENCES event_outbox (id) ON DELETE CASCADE,
+	recipient    TEXT NOT NULL,
+	delivered_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (event_id, recipient)
+);
+
+CREATE TABLE IF NOT EXISTS pending_notifications (
+	id         BIGSERIAL PRIMARY KEY,
+	kind       TEXT NOT NULL,
+	chat_id    BIGINT NOT NULL,
+	target     TEXT NOT NULL DEFAULT '',
+	due        TIMESTAMPTZ NOT NULL,
+	attempts   INT NOT NULL DEFAULT 0,
+	payload    JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS dead_letters (
+	id          BIGSERIAL PRIMARY KEY,
+	channel     TEXT NOT NULL DEFAULT 'telegram',
+	chat_id     BIGINT NOT NULL,
+	thread_id   INT NOT NULL DEFAULT 0,
+	target      TEXT NOT NULL DEFAULT '',
+	text        TEXT NOT NULL,
+	silent      BOOLEAN NOT NULL DEFAULT false,
+	photo       BYTEA,
+	payload     BYTEA,
+	error       TEXT NOT NULL,
+	attempts    INT NOT NULL,
+	failed_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	replayed_at TIMESTAMPTZ
+);
+`
+
+type PostgresStore struct {
+	db *database.PostgresDB
+}
+
+func NewPostgresStore(ctx context.Context, db *database.PostgresDB) (*PostgresStore, error) {
+	if _, err := db.Pool().Exec(ctx, schema); err != nil {
+		return nil, fmt.Errorf("unable to create schema: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) LoadConfigs(ctx context.Context) (map[int64]*models.MonitoringConfig, error) {
+	rows, err := s.db.Pool().Query(ctx, `SELECT chat_id, config FROM chat_configs`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load chat configs: %w", err)
+	}
+	defer rows.Close()
+
+	configs := make(map[int64]*models.MonitoringConfig)
+	for rows.Next() {
+		var (
+			chatID int64
+			data   []byte
+		)
+		if err := rows.Scan(&chatID, &data); err != nil {
+			return nil, fmt.Errorf("unable to scan chat config: %w", err)
+		}
+
+		var config models.MonitoringConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("unable to decode config of chat %d: %w", chatID, err)
+		}
+		configs[chatID] = &config
+	}
+
+	return configs, rows.Err()
+}
+
+func (s *PostgresStore) SaveConfig(ctx context.Context, chatID int64, config *models.MonitoringConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("unable to encode config of chat %d: %w", chatID, err)
+	}
+
+	_, err = s.db.Pool().Exec(ctx, `
+		INSERT INTO chat_configs (chat_id, config, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (chat_id) DO UPDATE SET config = EXCLUDED.config, updated_at = now()`,
+		chatID, data)
+	if err != nil {
+		return fmt.Errorf("unable to save config of chat %d: %w", chatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteConfig(ctx context.Context, chatID int64) error {
+	if _, err := s.db.Pool().Exec(ctx, `DELETE FROM chat_configs WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("unable to delete config of chat %d: %w", chatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) LoadAccount(ctx context.Context, account string) (*models.AccountSnapshot, error) {
+	var data []byte
+	err := s.db.Pool().QueryRow(ctx, `SELECT state FROM account_states WHERE account = $1`, strings.ToLower(account)).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to load state of account %s: %w", account, err)
+	}
+
+	var snapshot models.AccountSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to decode state of account %s: %w", account, err)
+	}
+	return &snapshot, nil
+}
+
+func (s *PostgresStore) SavePoll(ctx context.Context, account string, snapshot models.AccountSnapshot, events []models.Event) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to encode state of account %s: %w", account, err)
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	key := strings.ToLower(account)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO account_states (account, state, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (account) DO UPDATE SET state = EXCLUDED.state, updated_at = now()`,
+		key, data)
+	if err != nil {
+		return fmt.Errorf("unable to save state of account %s: %w", account, err)
+	}
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("unable to encode %s event: %w", event.Kind(), err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO event_outbox (account, kind, payload) VALUES ($1, $2, $3)`,
+			key, string(event.Kind()), payload); err != nil {
+			return fmt.Errorf("unable to add event to outbox: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit poll of account %s: %w", account, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) PendingEvents(ctx context.Context, now time.Time, limit int) ([]OutboxEvent, error) {
+	rows, err := s.db.Pool().Query(ctx, `
+		SELECT id, kind, payload, attempts FROM event_outbox
+		WHERE status = 'pending' AND next_attempt <= $1
+		ORDER BY id
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		result    []OutboxEvent
+		malformed = make(map[int64]error)
+	)
+	for rows.Next() {
+		var (
+			record OutboxEvent
+			kind   string
+			data   []byte
+		)
+		if err := rows.Scan(&record.ID, &kind, &data, &record.Attempts); err != nil {
+			return nil, fmt.Errorf("unable to scan outbox event: %w", err)
+		}
+		if record.Event, err = models.DecodeEvent(models.EventType(kind), data); err != nil {
+			malformed[record.ID] = err
+			continue
+		}
+		result = append(result, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Повтор не поможет: такие события откладываются, чтобы не блокировать остальные.
+	for id, decodeErr := range malformed {
+		log.Printf("Unable to decode outbox event %d: %v", id, decodeErr)
+		if err := s.MarkFailed(ctx, id, decodeErr.Error(), time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *PostgresStore) MarkDelivered(ctx context.Context, id int64) error {
+	_, err := s.db.Pool().Exec(ctx, `UPDATE event_outbox SET status = 'delivered', delivered_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("unable to mark event %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeliveredTo(ctx context.Context, id int64) ([]string, error) {
+	rows, err := s.db.Pool().Query(ctx, `SELECT recipient FROM event_deliveries WHERE event_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load deliveries of event %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	var recipients []string
+	for rows.Next() {
+		var recipient string
+		if err := rows.Scan(&recipient); err != nil {
+			return nil, fmt.Errorf("unable to scan delivery: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+func (s *PostgresStore) MarkDeliveredTo(ctx context.Context, id int64, recipient string) error {
+	_, err := s.db.Pool().Exec(ctx, `
+		INSERT INTO event_deliveries (event_id, recipient) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, id, recipient)
+	if err != nil {
+		return fmt.Errorf("unable to record delivery of event %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	status := outboxPending
+	if retryAt.IsZero() {
+		status = outboxFailed
+		retryAt = time.Now()
+	}
+
+	_, err := s.db.Pool().Exec(ctx, `
+		UPDATE event_outbox
+		SET attempts = attempts + 1, last_error = $2, next_attempt = $3, status = $4
+		WHERE id = $1`, id, reason, retryAt, status)
+	if err != nil {
+		return fmt.Errorf("unable to record failed delivery of event %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) RetryEvent(ctx context.Context, id int64) error {
+	_, err := s.db.Pool().Exec(ctx, `
+		UPDATE event_outbox SET status = 'pending', attempts = 0, next_attempt = now()
+		WHERE id = $1 AND status = 'failed'`, id)
+	if err != nil {
+		return fmt.Errorf("unable to retry event %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) PruneDelivered(ctx context.Context, before time.Time) error {
+	_, err := s.db.Pool().Exec(ctx, `DELETE FROM event_outbox WHERE status = 'delivered' AND delivered_at < $1`, before)
+	if err != nil {
+		return fmt.Errorf("unable to prune outbox: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) AddPending(ctx context.Context, item PendingNotification) (int64, error) {
+	var id int64
+	err := s.db.Pool().QueryRow(ctx, `
+		INSERT INTO pending_notifications (kind, chat_id, target, due, attempts, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		item.Kind, item.ChatID, item.Target, item.Due, item.Attempts, item.Payload).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to save pending notification for chat %d: %w", item.ChatID, err)
+	}
+	return id, nil
+}
+
+func (s *PostgresStore) LoadPending(ctx context.Context) ([]PendingNotification, error) {
+	rows, err := s.db.Pool().Query(ctx, `SELECT id, kind, chat_id, target, due, attempts, payload FROM pending_notifications ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var result []PendingNotification
+	for rows.Next() {
+		var item PendingNotification
+		if err := rows.Scan(&item.ID, &item.Kind, &item.ChatID, &item.Target, &item.Due, &item.Attempts, &item.Payload); err != nil {
+			return nil, fmt.Errorf("unable to scan pending notification: %w", err)
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+func (s *PostgresStore) DeletePending(ctx context.Context, ids []int64) error {
+	if _, err := s.db.Pool().Exec(ctx, `DELETE FROM pending_notifications WHERE id = ANY($1)`, ids); err != nil {
+		return fmt.Errorf("unable to delete pending notifications: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ReschedulePending(ctx context.Context, id int64, due time.Time, attempts int) error {
+	_, err := s.db.Pool().Exec(ctx, `UPDATE pending_notifications SET due = $2, attempts = $3 WHERE id = $1`, id, due, attempts)
+	if err != nil {
+		return fmt.Errorf("unable to reschedule pending notification %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) MovePending(ctx context.Context, oldChatID, newChatID int64) error {
+	_, err := s.db.Pool().Exec(ctx, `UPDATE pending_notifications SET chat_id = $2 WHERE chat_id = $1`, oldChatID, newChatID)
+	if err != nil {
+		return fmt.Errorf("unable to move pending notifications of chat %d: %w", oldChatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) AddDeadLetter(ctx context.Context, letter models.DeadLetter) error {
+	_, err := s.db.Pool().Exec(ctx, `
+		INSERT INTO dead_letters (channel, chat_id, thread_id, target, text, silent, photo, payload, error, attempts, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		letter.Channel, letter.ChatID, letter.ThreadID, letter.Target, letter.Text, letter.Silent, letter.Photo, letter.Payload,
+		letter.Error, letter.Attempts, letter.FailedAt)
+	if err != nil {
+		return fmt.Errorf("unable to save dead letter for chat %d: %w", letter.ChatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ListDeadLetters(ctx context.Context, limit int) ([]models.DeadLetter, error) {
+	rows, err := s.db.Pool().Query(ctx, `
+		SELECT id, channel, chat_id, thread_id, target, text, silent, error, attempts, failed_at FROM dead_letters
+		WHERE replayed_at IS NULL
+		ORDER BY id DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var result []models.DeadLetter
+	for rows.Next() {
+		var letter models.DeadLetter
+		if err := rows.Scan(&letter.ID, &letter.Channel, &letter.ChatID, &letter.ThreadID, &letter.Target, &letter.Text, &letter.Silent,
+			&letter.Error, &letter.Attempts, &letter.FailedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan dead letter: %w", err)
+		}
+		result = append(result, letter)
+	}
+	return result, rows.Err()
+}
+
+const deadLetterColumns = `id, channel, chat_id, thread_id, target, text, silent, photo, payload, error, attempts, failed_at, replayed_at`
+
+func scanDeadLetter(row pgx.Row) (*models.DeadLetter, error) {
+	var letter models.DeadLetter
+	err := row.Scan(&letter.ID, &letter.Channel, &letter.ChatID, &letter.ThreadID, &letter.Target, &letter.Text, &letter.Silent,
+		&letter.Photo, &letter.Payload, &letter.Error, &letter.Attempts, &letter.FailedAt, &letter.ReplayedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &letter, nil
+}
+
+func (s *PostgresStore) GetDeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	letter, err := scanDeadLetter(s.db.Pool().QueryRow(ctx, `SELECT `+deadLetterColumns+` FROM dead_letters WHERE id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load dead letter %d: %w", id, err)
+	}
+	return letter, nil
+}
+
+func (s *PostgresStore) ClaimDeadLetter(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	letter, err := scanDeadLetter(s.db.Pool().QueryRow(ctx, `
+		UPDATE dead_letters SET replayed_at = now()
+		WHERE id = $1 AND replayed_at IS NULL
+		RETURNING `+deadLetterColumns, id))
+	if err != nil {
+		return nil, fmt.Errorf("unable to claim dead letter %d: %w", id, err)
+	}
+	return letter, nil
+}
+
+func (s *PostgresStore) ReleaseDeadLetter(ctx context.Context, id int64) error {
+	if _, err := s.db.Pool().Exec(ctx, `UPDATE dead_letters SET replayed_at = NULL WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("unable to release dead letter %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteDeadLetter(ctx context.Context, id int64) error {
+	if _, err := s.db.Pool().Exec(ctx, `DELETE FROM dead_letters WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("unable to delete dead letter %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) MoveDeadLetters(ctx context.Context, oldChatID, newChatID int64) error {
+	_, err := s.db.Pool().Exec(ctx, `UPDATE dead_letters SET chat_id = $2 WHERE chat_id = $1`, oldChatID, newChatID)
+	if err != nil {
+		return fmt.Errorf("unable to move dead letters of chat %d: %w", oldChatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Close() {
+	s.db.Close()
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/pkg/database"
+)
+
+type ConfigStore interface {
+	LoadConfigs(ctx context.Context) (map[int64]*models.MonitoringConfig, error)
+	SaveConfig(ctx context.Context, chatID int64, config *models.MonitoringConfig) error
+	DeleteConfig(ctx context.Context, chatID int64) error
+}
+
+type Store interface {
+	ConfigStore
+	DeliveryStore
+	Close()
+}
+
+// Open подключается к Postgres, если задана строка подключения.
+// Без неё настройки хранятся только в памяти и теряются при перезапуске.
+func Open(ctx context.Context, connString string) (Store, error) {
+	if connString == "" {
+		log.Printf("Warning: DATABASE_URL is not set, chat settings and undelivered events will be lost on restart")
+		return NewMemoryStore(), nil
+	}
+
+	db, err := database.NewPostgresDB(ctx, connString)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := NewPostgresStore(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prepare storage: %w", err)
+	}
+	return store, nil
+}
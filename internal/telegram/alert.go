@@ -0,0 +1,172 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/filter"
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const alertUsage = "Срочные уведомления приходят сразу и со звуком, минуя сводки и тихие часы; /snooze придерживает и их.\n" +
+	"/alert add <слово> - добавить ключевое слово, например: /alert add BREAKING\n" +
+	"/alert regex <выражение> - добавить регулярное выражение, например: /alert regex CVE-\\d{4}-\\d+\n" +
+	"/alert remove <номер> - удалить правило\n" +
+	"/alert defaults - включить правила по умолчанию: BREAKING, security, revert и CVE\n" +
+	"/alert clear - отключить срочные уведомления\n" +
+	"/alert escalate <ID чата>|off - дублировать срочные уведомления в другой чат"
+
+func (b *Bot) handleAlert(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) == 0 {
+		b.sendAlerts(chatID)
+		return
+	}
+
+	switch command := strings.ToLower(args[0]); command {
+	case "add", "regex":
+		if len(args) < 2 {
+			b.SendMessage(chatID, alertUsage)
+			return
+		}
+		rule := models.AlertRule{Pattern: strings.Join(args[1:], " "), Keyword: command == "add"}
+		if err := filter.ValidateAlert(rule); err != nil {
+			b.SendMessage(chatID, "Некорректное правило: "+html.EscapeString(err.Error()))
+			return
+		}
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		config.AlertRules = append(append([]models.AlertRule{}, config.AlertRules...), rule)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Правило добавлено: "+formatAlertRule(rule))
+
+	case "remove":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите номер правила: /alert remove 1")
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		rules := append([]models.AlertRule{}, config.AlertRules...)
+		if err != nil || index < 1 || index > len(rules) {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, "Правила с таким номером нет. Список правил: /alert")
+			return
+		}
+		config.AlertRules = append(rules[:index-1], rules[index:]...)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, fmt.Sprintf("Правило %d удалено.", index))
+
+	case "defaults", "reset":
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).AlertRules = append([]models.AlertRule{}, models.DefaultAlertRules...)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Включены правила по умолчанию: BREAKING, security, revert и идентификаторы CVE.")
+
+	case "clear":
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).AlertRules = nil
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Срочные уведомления отключены.")
+
+	case "escalate":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите ID чата: /alert escalate -1001234567890 или /alert escalate off")
+			return
+		}
+		b.handleEscalate(update, args[1])
+
+	default:
+		b.SendMessage(chatID, alertUsage)
+	}
+}
+
+func (b *Bot) handleEscalate(update tgbotapi.Update, arg string) {
+	chatID := update.Message.Chat.ID
+
+	if strings.EqualFold(arg, "off") {
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).EscalationChatID = 0
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Дублирование срочных уведомлений отключено.")
+		return
+	}
+
+	target, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || target == 0 {
+		b.SendMessage(chatID, "ID чата должен быть числом, например -1001234567890")
+		return
+	}
+
+	// Писать в чужой чат можно, только если отправитель сам в нём состоит.
+	if update.Message.From == nil || !b.isChatMember(target, update.Message.From.ID) {
+		b.SendMessage(chatID, "Не удалось подтвердить, что вы состоите в этом чате. Добавьте бота в чат эскалации и повторите команду.")
+		return
+	}
+
+	b.configMutex.Lock()
+	b.getOrCreateConfig(chatID).EscalationChatID = target
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, fmt.Sprintf("Срочные уведомления будут дублироваться в чат %d.", target))
+}
+
+func (b *Bot) isChatMember(chatID, userID int64) bool {
+	if chatID == userID {
+		return true
+	}
+
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: chatID,
+			UserID: userID,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to get chat member %d in chat %d: %v", userID, chatID, err)
+		return false
+	}
+
+	return member.Status != "left" && member.Status != "kicked"
+}
+
+func (b *Bot) sendAlerts(chatID int64) {
+	config, _ := b.GetConfig(chatID)
+	rules := config.AlertRules
+
+	text := "Правила срочных уведомлений:\n"
+	if len(rules) == 0 {
+		text += "нет, срочные уведомления отключены\n"
+	}
+	for i, rule := range rules {
+		text += fmt.Sprintf("%d. %s\n", i+1, formatAlertRule(rule))
+	}
+
+	if config.EscalationChatID != 0 {
+		text += fmt.Sprintf("Дублирование в чат: %d\n", config.EscalationChatID)
+	}
+
+	b.SendMessage(chatID, text+"\n"+alertUsage)
+}
+
+func formatAlertRule(rule models.AlertRule) string {
+	text := "<code>" + html.EscapeString(rule.Pattern) + "</code>"
+	if !rule.Keyword {
+		text += " (regexp)"
+	}
+	return text
+}
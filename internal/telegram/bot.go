@@ -1,39 +1,49 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/storage"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-type MonitoringConfig struct {
-	GitHubUsername      string
-	CheckIntervalMinutes int
-	IsActive            bool
-}
+const (
+	defaultCheckIntervalMinutes = 5
+	storeTimeout                = 5 * time.Second
+)
 
 type Bot struct {
-	api              *tgbotapi.BotAPI
-	monitoringConfigs map[int64]*MonitoringConfig
-	configMutex      sync.RWMutex
-	commandHandlers  map[string]func(update tgbotapi.Update)
-	updateChan       chan tgbotapi.Update
-	callbackChan     chan MonitoringCallback
+	api               *tgbotapi.BotAPI
+	monitoringConfigs map[int64]*models.MonitoringConfig
+	configMutex       sync.RWMutex
+	commandHandlers   map[string]func(update tgbotapi.Update)
+	updateChan        chan tgbotapi.Update
+	callbackChan      chan MonitoringCallback
+	done              chan struct{} // закрывается в Stop
+	sender            *sender
+	store             storage.ConfigStore
+	sendEmailCode     func(ctx context.Context, to, code string) error // отправка кода подтверждения /email; nil, если SMTP не настроен
+	admins            map[int64]bool // пользователи, которым доступна /deadletters
 }
 
 type MonitoringCallback struct {
-	Type      string // "start", "stop", "update"
+	Type      string // "start", "stop", "update", "migrate", "pause", "resume", "check", "changelog", "report", "chart", "deadletters"
 	ChatID    int64
 	Username  string
 	Interval  int
+	NewChatID int64    // только для "migrate"
+	Args      []string // аргументы команды: "changelog", "report", "chart", "deadletters"
 }
 
-func NewBot(token string) (*Bot, error) {
+func NewBot(token string, store storage.ConfigStore) (*Bot, error) {
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
@@ -42,89 +52,318 @@ func NewBot(token string) (*Bot, error) {
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
 	b := &Bot{
-		api:              bot,
-		monitoringConfigs: make(map[int64]*MonitoringConfig),
-		configMutex:      sync.RWMutex{},
-		updateChan:       make(chan tgbotapi.Update, 100),
-		callbackChan:     make(chan MonitoringCallback, 100),
+		api:               bot,
+		monitoringConfigs: make(map[int64]*models.MonitoringConfig),
+		configMutex:       sync.RWMutex{},
+		updateChan:        make(chan tgbotapi.Update, 100),
+		callbackChan:      make(chan MonitoringCallback, 100),
+		done:              make(chan struct{}),
+		sender:            newSender(bot),
+		store:             store,
 	}
 
+	b.sender.onUnavailable = b.deactivateChat
+	b.sender.onMigrated = b.migrateChat
+	go b.sender.run()
+
 	b.commandHandlers = map[string]func(update tgbotapi.Update){
-		"start":    b.handleStart,
-		"help":     b.handleHelp,
-		"status":   b.handleStatus,
-		"track":    b.handleTrack,
-		"interval": b.handleInterval,
-		"stop":     b.handleStop,
+		"start":       b.handleStart,
+		"help":        b.handleHelp,
+		"status":      b.handleStatus,
+		"track":       b.handleTrack,
+		"interval":    b.handleInterval,
+		"stop":        b.handleStop,
+		"pause":       b.handlePause,
+		"resume":      b.handleResume,
+		"check":       b.handleCheck,
+		"access":      b.handleAccess,
+		"route":       b.handleRoute,
+		"digest":      b.handleDigest,
+		"timezone":    b.handleTimezone,
+		"quiet":       b.handleQuiet,
+		"snooze":      b.handleSnooze,
+		"settings":    b.handleSettings,
+		"filter":      b.handleFilter,
+		"alert":       b.handleAlert,
+		"secrets":     b.handleSecrets,
+		"sink":        b.handleSink,
+		"email":       b.handleEmail,
+		"deadletters": b.handleDeadLetters,
+		"changelog":   b.handleChangelog,
+		"report":      b.handleReport,
+		"chart":       b.handleChart,
 	}
 
 	return b, nil
 }
 
 func (b *Bot) SendMessage(chatID int64, text string) error {
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = tgbotapi.ModeHTML
-	_, err := b.api.Send(msg)
-	if err != nil {
+	return b.Send(OutgoingMessage{ChatID: chatID, Text: text})
+}
+
+func (b *Bot) Send(msg OutgoingMessage) error {
+	if err := b.sender.enqueue(msg); err != nil {
 		return fmt.Errorf("failed to send message: %w", err)
 	}
 	return nil
 }
 
+// Requeue ставит сообщение в очередь, как Send, но не сохраняет его в недоставленных, если очередь
+// его не приняла: вызывающий сам решает, что делать с ошибкой.
+func (b *Bot) Requeue(msg OutgoingMessage) error {
+	return b.sender.enqueueQueued(&queuedMessage{OutgoingMessage: msg})
+}
+
+// Deliver ставит сообщение в очередь и ждёт, пока его примет Telegram. Если отправитель
+// исчерпал попытки, возвращается DeliveryFailure: сообщение уже передано обработчику ошибок.
+// Сообщение, которое очередь не приняла, не сохраняется: его повторяет вызывающий.
+func (b *Bot) Deliver(ctx context.Context, msg OutgoingMessage) error {
+	queued := &queuedMessage{OutgoingMessage: msg, result: make(chan error, 1)}
+	if err := b.sender.enqueueQueued(queued); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	select {
+	case err := <-queued.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetConfig возвращает копию настроек чата, которую можно читать без блокировок.
+func (b *Bot) GetConfig(chatID int64) (models.MonitoringConfig, bool) {
+	b.configMutex.RLock()
+	defer b.configMutex.RUnlock()
+
+	config, exists := b.monitoringConfigs[chatID]
+	if !exists {
+		return models.MonitoringConfig{}, false
+	}
+	return config.Clone(), true
+}
+
+// ActiveChats возвращает чаты с активной подпиской.
+func (b *Bot) ActiveChats() []int64 {
+	b.configMutex.RLock()
+	defer b.configMutex.RUnlock()
+
+	var chats []int64
+	for chatID, config := range b.monitoringConfigs {
+		if config.IsActive && config.GitHubUsername != "" {
+			chats = append(chats, chatID)
+		}
+	}
+	return chats
+}
+
+// UpdateConfig меняет настройки чата и сохраняет их в хранилище.
+func (b *Bot) UpdateConfig(chatID int64, update func(config *models.MonitoringConfig)) {
+	b.configMutex.Lock()
+	update(b.getOrCreateConfig(chatID))
+	b.configMutex.Unlock()
+
+	b.saveConfig(chatID)
+}
+
+// SetEmailSender включает команду /email: send отправляет письмо с кодом подтверждения адреса.
+// Вызывается до запуска приёма обновлений.
+func (b *Bot) SetEmailSender(send func(ctx context.Context, to, code string) error) {
+	b.sendEmailCode = send
+}
+
 func (b *Bot) GetCallbackChannel() <-chan MonitoringCallback {
 	return b.callbackChan
 }
 
+// SetFailureHandler задаёт обработчик сообщений, которые не удалось доставить; вызывается до запуска приёма обновлений.
+func (b *Bot) SetFailureHandler(handle func(failure DeliveryFailure)) {
+	b.sender.onFailure = handle
+}
+
+func (b *Bot) Stop() {
+	b.api.StopReceivingUpdates()
+	close(b.done)
+	b.sender.stop()
+}
+
+// notifyMonitor передаёт колбэк менеджеру мониторинга. Вызывается в том числе
+// из горутин очереди отправки, которых дожидается Stop, поэтому после остановки
+// бота колбэк отбрасывается, а не блокирует завершение.
+func (b *Bot) notifyMonitor(callback MonitoringCallback) {
+	select {
+	case b.callbackChan <- callback:
+	case <-b.done:
+		log.Printf("Bot is stopped, dropping %q callback for chat %d", callback.Type, callback.ChatID)
+	}
+}
+
 func (b *Bot) StartCommandListener() {
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("Failed to delete webhook before polling: %v", err)
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := b.api.GetUpdatesChan(u)
 
 	for update := range updates {
-		if update.Message == nil {
-			continue
-		}
+		b.HandleUpdate(update)
+	}
+}
+
+// SetWebhook переключает бота на получение обновлений через webhook.
+// Telegram будет передавать secret в заголовке X-Telegram-Bot-Api-Secret-Token.
+func (b *Bot) SetWebhook(url, secret string) error {
+	params := tgbotapi.Params{}
+	params["url"] = url
+	params.AddNonEmpty("secret_token", secret)
 
-		chatID := update.Message.Chat.ID
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
 
-		if update.Message.IsCommand() {
-			command := update.Message.Command()
-			if handler, ok := b.commandHandlers[command]; ok {
-				handler(update)
-			} else {
+// HandleUpdate - общий диспетчер для long polling и webhook.
+func (b *Bot) HandleUpdate(update tgbotapi.Update) {
+	if update.MyChatMember != nil {
+		b.handleMyChatMember(update.MyChatMember)
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		b.handleCallbackQuery(update.CallbackQuery)
+		return
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+
+	if update.Message.MigrateToChatID != 0 {
+		b.migrateChat(chatID, update.Message.MigrateToChatID)
+		return
+	}
+
+	if update.Message.IsCommand() {
+		if !b.isAddressedToMe(update.Message) {
+			return
+		}
+
+		command := update.Message.Command()
+		handler, ok := b.commandHandlers[command]
+		if !ok {
+			// В группе команда без @имени может предназначаться другому боту,
+			// поэтому отвечаем только на явно адресованные нам.
+			if !isGroupChat(update.Message.Chat) || strings.Contains(update.Message.CommandWithAt(), "@") {
 				b.SendMessage(chatID, "Неизвестная команда. Используйте /help для справки.")
 			}
-		} else if update.Message.Text != "" {
-			username := b.extractGitHubUsername(update.Message.Text)
-			if username != "" {
-				b.configMutex.Lock()
-				if _, exists := b.monitoringConfigs[chatID]; !exists {
-					b.monitoringConfigs[chatID] = &MonitoringConfig{
-						GitHubUsername:      username,
-						CheckIntervalMinutes: 5, // По умолчанию 5 минут
-						IsActive:            true,
-					}
-				} else {
-					b.monitoringConfigs[chatID].GitHubUsername = username
-					b.monitoringConfigs[chatID].IsActive = true
-				}
-				b.configMutex.Unlock()
-
-				b.callbackChan <- MonitoringCallback{
-					Type:     "start",
-					ChatID:   chatID,
-					Username: username,
-					Interval: b.monitoringConfigs[chatID].CheckIntervalMinutes,
-				}
-
-				b.SendMessage(chatID, fmt.Sprintf("Начинаю отслеживать GitHub аккаунт: <b>%s</b>\nИнтервал проверки: %d минут", 
-					username, b.monitoringConfigs[chatID].CheckIntervalMinutes))
+			return
+		}
+
+		if managementCommands[command] && !b.canManage(update.Message) {
+			b.SendMessage(chatID, "Эту команду в группе могут выполнять только администраторы.")
+			return
+		}
+
+		handler(update)
+
+		if managementCommands[command] {
+			b.saveConfig(chatID)
+		}
+	} else if update.Message.Text != "" && !isGroupChat(update.Message.Chat) {
+		// В группах имя пользователя принимается только через /track, чтобы не реагировать на обычную переписку.
+		username := b.extractGitHubUsername(update.Message.Text)
+		if username != "" {
+			b.configMutex.Lock()
+			config := b.getOrCreateConfig(chatID)
+			config.GitHubUsername = username
+			config.IsActive = true
+			config.Paused = false
+			interval := config.CheckIntervalMinutes
+			b.configMutex.Unlock()
+
+			b.saveConfig(chatID)
+
+			b.callbackChan <- MonitoringCallback{
+				Type:     "start",
+				ChatID:   chatID,
+				Username: username,
+				Interval: interval,
 			}
+
+			b.SendMessage(chatID, fmt.Sprintf("Начинаю отслеживать GitHub аккаунт: <b>%s</b>\nИнтервал проверки: %d минут", 
+				username, interval))
 		}
 	}
 }
 
+// RestoreConfigs загружает сохранённые настройки и возобновляет мониторинг активных подписок.
+// Вызывается после запуска обработчика колбэков.
+func (b *Bot) RestoreConfigs(ctx context.Context) error {
+	configs, err := b.store.LoadConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore configs: %w", err)
+	}
+
+	b.configMutex.Lock()
+	for chatID, config := range configs {
+		migrateRoutes(config)
+		b.monitoringConfigs[chatID] = config
+	}
+	b.configMutex.Unlock()
+
+	restored := 0
+	for chatID, config := range configs {
+		if !config.IsActive || config.GitHubUsername == "" {
+			continue
+		}
+		b.callbackChan <- MonitoringCallback{
+			Type:     "start",
+			ChatID:   chatID,
+			Username: config.GitHubUsername,
+			Interval: config.CheckIntervalMinutes,
+		}
+		if config.Paused {
+			b.callbackChan <- MonitoringCallback{Type: "pause", ChatID: chatID}
+		}
+		restored++
+	}
+
+	log.Printf("Restored %d chat configs, %d active subscriptions", len(configs), restored)
+	return nil
+}
+
+func (b *Bot) saveConfig(chatID int64) {
+	config, exists := b.GetConfig(chatID)
+	if !exists {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := b.store.SaveConfig(ctx, chatID, &config); err != nil {
+		log.Printf("Failed to save config of chat %d: %v", chatID, err)
+	}
+}
+
+// getOrCreateConfig должен вызываться под b.configMutex.
+func (b *Bot) getOrCreateConfig(chatID int64) *models.MonitoringConfig {
+	config, exists := b.monitoringConfigs[chatID]
+	if !exists {
+		config = &models.MonitoringConfig{
+			CheckIntervalMinutes: defaultCheckIntervalMinutes,
+		}
+		b.monitoringConfigs[chatID] = config
+	}
+	return config
+}
+
 func (b *Bot) extractGitHubUsername(text string) string {
 	text = strings.TrimSpace(text)
 	
@@ -154,7 +393,24 @@ func (b *Bot) handleHelp(update tgbotapi.Update) {
 		"/track <username> - Начать отслеживание GitHub аккаунта\n" +
 		"/interval <минуты> - Установить интервал проверки\n" +
 		"/status - Показать статус мониторинга\n" +
-		"/stop - Остановить мониторинг\n\n" +
+		"/stop - Остановить мониторинг\n" +
+		"/pause, /resume - Приостановить и возобновить уведомления\n" +
+		"/check - Проверить аккаунт прямо сейчас\n" +
+		"/access admins|all - Кто может управлять подписками в группе\n" +
+		"/route <аккаунт|репозиторий> <ID темы> - Отправлять уведомления в тему форума\n" +
+		"/digest - Настроить сводки вместо мгновенных уведомлений\n" +
+		"/timezone <зона> - Часовой пояс чата, например Europe/Moscow\n" +
+		"/quiet ЧЧ:ММ-ЧЧ:ММ [hold|silent] - Тихие часы\n" +
+		"/snooze <длительность> - Отложить уведомления, например 2h\n" +
+		"/settings - Выбрать типы уведомлений\n" +
+		"/filter - Правила фильтрации коммитов по автору, сообщению, ветке и путям\n" +
+		"/alert - Срочные уведомления по ключевым словам (BREAKING, security, CVE)\n" +
+		"/secrets - Проверка коммитов на утечку ключей и токенов\n" +
+		"/sink - Дублировать уведомления в Slack, Discord или webhook\n" +
+		"/email - Сводки событий по почте\n" +
+		"/changelog <репозиторий> [от] [до] - Changelog по Conventional Commits между тегами или коммитами\n" +
+		"/report - Еженедельный отчёт по аккаунту (/report schedule - расписание)\n" +
+		"/chart commits|heatmap|languages - Графики активности аккаунта\n\n" +
 		"Вы также можете просто отправить имя пользователя GitHub или URL профиля."
 	b.SendMessage(chatID, helpText)
 }
@@ -171,11 +427,16 @@ func (b *Bot) handleStatus(update tgbotapi.Update) {
 		return
 	}
 	
+	status := "активен"
+	if config.Paused {
+		status = "приостановлен (/resume - возобновить)"
+	}
+
 	statusText := fmt.Sprintf("Статус мониторинга:\n" +
 		"• Отслеживаемый аккаунт: <b>%s</b>\n" +
 		"• Интервал проверки: %d минут\n" +
-		"• Статус: активен", 
-		config.GitHubUsername, config.CheckIntervalMinutes)
+		"• Статус: %s",
+		config.GitHubUsername, config.CheckIntervalMinutes, status)
 	
 	b.SendMessage(chatID, statusText)
 }
@@ -192,17 +453,11 @@ func (b *Bot) handleTrack(update tgbotapi.Update) {
 	username := args[0]
 	
 	b.configMutex.Lock()
-	if _, exists := b.monitoringConfigs[chatID]; !exists {
-		b.monitoringConfigs[chatID] = &MonitoringConfig{
-			GitHubUsername:      username,
-			CheckIntervalMinutes: 5, // По умолчанию 5 минут
-			IsActive:            true,
-		}
-	} else {
-		b.monitoringConfigs[chatID].GitHubUsername = username
-		b.monitoringConfigs[chatID].IsActive = true
-	}
-	interval := b.monitoringConfigs[chatID].CheckIntervalMinutes
+	config := b.getOrCreateConfig(chatID)
+	config.GitHubUsername = username
+	config.IsActive = true
+	config.Paused = false
+	interval := config.CheckIntervalMinutes
 	b.configMutex.Unlock()
 	
 	b.callbackChan <- MonitoringCallback{
@@ -232,7 +487,7 @@ func (b *Bot) handleInterval(update tgbotapi.Update) {
 	}
 	
 	b.configMutex.Lock()
-	if _, exists := b.monitoringConfigs[chatID]; !exists {
+	if config, exists := b.monitoringConfigs[chatID]; !exists || config.GitHubUsername == "" {
 		b.SendMessage(chatID, "Сначала укажите аккаунт для отслеживания с помощью команды /track <username>")
 		b.configMutex.Unlock()
 		return
@@ -276,3 +531,80 @@ func (b *Bot) handleStop(update tgbotapi.Update) {
 	
 	b.SendMessage(chatID, fmt.Sprintf("Мониторинг аккаунта <b>%s</b> остановлен.", username))
 }
+
+func (b *Bot) handleMyChatMember(member *tgbotapi.ChatMemberUpdated) {
+	status := member.NewChatMember
+	if status.HasLeft() || status.WasKicked() {
+		b.deactivateChat(member.Chat.ID, fmt.Errorf("bot status changed to %q", status.Status))
+	}
+}
+
+// deactivateChat останавливает мониторинг чата, в который бот больше не может писать.
+func (b *Bot) deactivateChat(chatID int64, reason error) {
+	b.configMutex.Lock()
+	config, exists := b.monitoringConfigs[chatID]
+	if !exists || !config.IsActive {
+		b.configMutex.Unlock()
+		return
+	}
+	config.IsActive = false
+	username := config.GitHubUsername
+	b.configMutex.Unlock()
+
+	log.Printf("Chat %d is unavailable, deactivating monitoring of %s: %v", chatID, username, reason)
+	b.saveConfig(chatID)
+
+	b.notifyMonitor(MonitoringCallback{
+		Type:     "stop",
+		ChatID:   chatID,
+		Username: username,
+	})
+}
+
+// migrateChat переносит настройки группы, преобразованной в супергруппу, на новый ID
+// и перенаправляет на него дублирование срочных уведомлений из других чатов.
+func (b *Bot) migrateChat(oldChatID, newChatID int64) {
+	b.sender.mu.Lock()
+	b.sender.redirect(oldChatID, newChatID)
+	b.sender.mu.Unlock()
+
+	b.configMutex.Lock()
+	var escalating []int64
+	for chatID, config := range b.monitoringConfigs {
+		if config.EscalationChatID == oldChatID {
+			config.EscalationChatID = newChatID
+			escalating = append(escalating, chatID)
+		}
+	}
+	config, exists := b.monitoringConfigs[oldChatID]
+	username := ""
+	if exists {
+		delete(b.monitoringConfigs, oldChatID)
+		b.monitoringConfigs[newChatID] = config
+		username = config.GitHubUsername
+	}
+	b.configMutex.Unlock()
+
+	for _, chatID := range escalating {
+		b.saveConfig(chatID)
+	}
+
+	if exists {
+		log.Printf("Chat %d was migrated to %d", oldChatID, newChatID)
+		b.saveConfig(newChatID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := b.store.DeleteConfig(ctx, oldChatID); err != nil {
+			log.Printf("Failed to delete config of migrated chat %d: %v", oldChatID, err)
+		}
+	}
+
+	// Даже без своих настроек у чата могут быть отложенные сообщения: например, срочные уведомления других чатов.
+	b.notifyMonitor(MonitoringCallback{
+		Type:      "migrate",
+		ChatID:    oldChatID,
+		Username:  username,
+		NewChatID: newChatID,
+	})
+}
@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const changelogUsage = "Использование: /changelog <репозиторий> [от] [до]\n" +
+	"от и до - теги, ветки или SHA. По умолчанию - от последнего тега до ветки по умолчанию.\n" +
+	"Пример: /changelog api v1.2.0 v1.3.0 или /changelog owner/repo"
+
+// handleChangelog передаёт запрос менеджеру: у бота нет доступа к GitHub.
+func (b *Bot) handleChangelog(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) < 1 || len(args) > 3 {
+		b.SendMessage(chatID, changelogUsage)
+		return
+	}
+
+	owner, repo, found := strings.Cut(args[0], "/")
+	if !found {
+		config, _ := b.GetConfig(chatID)
+		if config.GitHubUsername == "" {
+			b.SendMessage(chatID, "Укажите репозиторий вместе с владельцем (owner/repo) или начните отслеживание командой /track <username>")
+			return
+		}
+		owner, repo = config.GitHubUsername, args[0]
+	}
+	if owner == "" || repo == "" {
+		b.SendMessage(chatID, changelogUsage)
+		return
+	}
+
+	b.callbackChan <- MonitoringCallback{
+		Type:     "changelog",
+		ChatID:   chatID,
+		Username: owner,
+		Args:     append([]string{repo}, args[1:]...),
+	}
+
+	b.SendMessage(chatID, "Собираю changelog для <b>"+html.EscapeString(owner+"/"+repo)+"</b>...")
+}
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultChartDays = 14
+	maxChartDays     = 90
+)
+
+const chartUsage = "Графики по отслеживаемому аккаунту:\n" +
+	"/chart commits [дней] - коммиты по дням (по умолчанию 14, максимум 90)\n" +
+	"/chart heatmap - календарь активности за полгода\n" +
+	"/chart languages - языки в репозиториях"
+
+func (b *Bot) handleChart(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(strings.ToLower(update.Message.CommandArguments()))
+
+	config, _ := b.GetConfig(chatID)
+	if config.GitHubUsername == "" {
+		b.SendMessage(chatID, "Сначала укажите аккаунт для отслеживания с помощью команды /track <username>")
+		return
+	}
+
+	kind := "commits"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	callbackArgs := []string{kind}
+	switch kind {
+	case "commits":
+		days := defaultChartDays
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed < 1 || parsed > maxChartDays {
+				b.SendMessage(chatID, "Укажите число дней от 1 до 90, например: /chart commits 30")
+				return
+			}
+			days = parsed
+		}
+		callbackArgs = append(callbackArgs, strconv.Itoa(days))
+	case "heatmap", "languages":
+	default:
+		b.SendMessage(chatID, chartUsage)
+		return
+	}
+
+	b.callbackChan <- MonitoringCallback{
+		Type:     "chart",
+		ChatID:   chatID,
+		Username: config.GitHubUsername,
+		Args:     callbackArgs,
+	}
+
+	b.SendMessage(chatID, "Строю график для <b>"+html.EscapeString(config.GitHubUsername)+"</b>...")
+}
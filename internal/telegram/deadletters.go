@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ParseAdminIDs разбирает список ID пользователей Telegram через запятую.
+func ParseAdminIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %q", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// SetAdmins задаёт администраторов бота; вызывается до запуска приёма обновлений.
+func (b *Bot) SetAdmins(ids []int64) {
+	b.admins = make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		b.admins[id] = true
+	}
+}
+
+func (b *Bot) isAdmin(user *tgbotapi.User) bool {
+	return user != nil && b.admins[user.ID]
+}
+
+// handleDeadLetters передаёт менеджеру команду администратора над недоставленными сообщениями.
+// Для остальных пользователей команды не существует. В недоставленных - сообщения всех чатов,
+// поэтому команда работает только в личном чате: иначе их прочитали бы участники группы.
+func (b *Bot) handleDeadLetters(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+
+	if !b.isAdmin(update.Message.From) {
+		b.SendMessage(chatID, "Неизвестная команда. Используйте /help для справки.")
+		return
+	}
+	if !update.Message.Chat.IsPrivate() {
+		b.SendMessage(chatID, "Команда доступна только в личном чате с ботом.")
+		return
+	}
+
+	b.callbackChan <- MonitoringCallback{
+		Type:   "deadletters",
+		ChatID: chatID,
+		Args:   strings.Fields(strings.ToLower(update.Message.CommandArguments())),
+	}
+}
@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const defaultDigestTime = "09:00"
+
+var eventTypeNames = map[string]models.EventType{
+	"repo":     models.EventNewRepo,
+	"new_repo": models.EventNewRepo,
+	"commit":   models.EventCommit,
+	"release":  models.EventRelease,
+	"pr":       models.EventPullRequest,
+	"ci":       models.EventCI,
+	"deps":     models.EventDependency,
+}
+
+var eventTypeOrder = []string{"repo", "commit", "release", "pr", "ci", "deps"}
+
+func (b *Bot) handleDigest(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(strings.ToLower(update.Message.CommandArguments()))
+
+	if len(args) == 0 {
+		b.sendDigestSettings(chatID)
+		return
+	}
+
+	var reply string
+
+	b.configMutex.Lock()
+	digest := &b.getOrCreateConfig(chatID).Digest
+
+	switch args[0] {
+	case "off":
+		digest.Mode = models.DigestOff
+		reply = "Сводки отключены, уведомления будут приходить сразу."
+
+	case models.DigestHourly:
+		digest.Mode = models.DigestHourly
+		reply = "Уведомления будут приходить ежечасной сводкой."
+
+	case models.DigestDaily:
+		at := defaultDigestTime
+		if len(args) > 1 {
+			if _, err := time.Parse("15:04", args[1]); err != nil {
+				reply = "Укажите время в формате ЧЧ:ММ, например: /digest daily 09:00"
+				break
+			}
+			at = args[1]
+		}
+		digest.Mode = models.DigestDaily
+		digest.At = at
+		reply = fmt.Sprintf("Уведомления будут приходить ежедневной сводкой в %s.", at)
+
+	case "group":
+		if len(args) < 2 || (args[1] != models.DigestGroupAccount && args[1] != models.DigestGroupRepo) {
+			reply = "Используйте /digest group account или /digest group repo"
+			break
+		}
+		digest.GroupBy = args[1]
+		reply = "Группировка сводки: " + digestGroupTitle(digest.GroupBy) + "."
+
+	case "exclude", "include":
+		if len(args) < 2 {
+			reply = "Укажите тип события: repo, commit, release, pr, ci или deps"
+			break
+		}
+		eventType, ok := eventTypeNames[args[1]]
+		if !ok {
+			reply = "Неизвестный тип события. Доступные типы: repo, commit, release, pr, ci, deps"
+			break
+		}
+		if digest.Excluded == nil {
+			digest.Excluded = make(map[models.EventType]bool)
+		}
+		if args[0] == "exclude" {
+			digest.Excluded[eventType] = true
+			reply = fmt.Sprintf("События %s будут приходить сразу, минуя сводку.", args[1])
+		} else {
+			delete(digest.Excluded, eventType)
+			reply = fmt.Sprintf("События %s снова попадают в сводку.", args[1])
+		}
+
+	default:
+		reply = "Доступные варианты:\n" +
+			"/digest off - мгновенные уведомления\n" +
+			"/digest hourly - сводка раз в час\n" +
+			"/digest daily [ЧЧ:ММ] - сводка раз в день\n" +
+			"/digest group account|repo - группировка сводки\n" +
+			"/digest exclude|include repo|commit|release|pr|ci|deps - доставлять тип события сразу"
+	}
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, reply)
+}
+
+func (b *Bot) sendDigestSettings(chatID int64) {
+	config, _ := b.GetConfig(chatID)
+	digest := config.Digest
+
+	mode := "выключены (мгновенные уведомления)"
+	switch digest.Mode {
+	case models.DigestHourly:
+		mode = "раз в час"
+	case models.DigestDaily:
+		mode = "раз в день в " + digest.At
+	}
+
+	var excluded []string
+	for _, name := range eventTypeOrder {
+		if digest.Excluded[eventTypeNames[name]] {
+			excluded = append(excluded, name)
+		}
+	}
+	if len(excluded) == 0 {
+		excluded = append(excluded, "нет")
+	}
+
+	b.SendMessage(chatID, "Сводки: <b>"+mode+"</b>\n"+
+		"Группировка: "+digestGroupTitle(digest.GroupBy)+"\n"+
+		"Сразу доставляются: "+strings.Join(excluded, ", ")+"\n\n"+
+		"Изменить: /digest off|hourly|daily [ЧЧ:ММ], /digest group account|repo, /digest exclude|include repo|commit|release|pr|ci|deps")
+}
+
+func digestGroupTitle(groupBy string) string {
+	if groupBy == models.DigestGroupRepo {
+		return "по репозиториям"
+	}
+	return "по аккаунту"
+}
@@ -0,0 +1,269 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"html"
+	"log"
+	"math/big"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const emailUsage = "Сводки событий по почте:\n" +
+	"/email add <адрес> [hourly|daily [ЧЧ:ММ]] - добавить адрес, по умолчанию сводка раз в день в 09:00\n" +
+	"/email confirm <код> - подтвердить адрес кодом из письма\n" +
+	"/email remove <номер> - удалить адрес\n\n" +
+	"Сводки приходят только на подтверждённые адреса. Каждый адрес получает сводку по своему расписанию, " +
+	"время - по часовому поясу чата (/timezone)."
+
+const (
+	// Код подтверждения действует сутки, после maxEmailCodeAttempts неверных попыток его нужно запросить заново.
+	emailCodeTTL         = 24 * time.Hour
+	maxEmailCodeAttempts = 5
+	emailCodeResend      = 10 * time.Minute // повторное письмо на тот же адрес - не чаще
+	maxUnconfirmedEmails = 3
+	emailCodeSendTimeout = time.Minute
+)
+
+func (b *Bot) handleEmail(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if b.sendEmailCode == nil {
+		b.SendMessage(chatID, "Отправка почты не настроена на сервере (переменные SMTP_*).")
+		return
+	}
+
+	if len(args) == 0 {
+		b.sendEmails(chatID)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(args) < 2 {
+			b.SendMessage(chatID, emailUsage)
+			return
+		}
+
+		address, err := mail.ParseAddress(args[1])
+		if err != nil {
+			b.SendMessage(chatID, "Некорректный адрес: "+html.EscapeString(args[1]))
+			return
+		}
+
+		recipient := models.EmailRecipient{Address: address.Address, Mode: models.DigestDaily, At: defaultDigestTime}
+		if len(args) > 2 {
+			switch strings.ToLower(args[2]) {
+			case models.DigestHourly:
+				recipient.Mode = models.DigestHourly
+				recipient.At = ""
+			case models.DigestDaily:
+				if len(args) > 3 {
+					if _, err := time.Parse("15:04", args[3]); err != nil {
+						b.SendMessage(chatID, "Укажите время в формате ЧЧ:ММ, например: /email add "+html.EscapeString(recipient.Address)+" daily 09:00")
+						return
+					}
+					recipient.At = args[3]
+				}
+			default:
+				b.SendMessage(chatID, emailUsage)
+				return
+			}
+		}
+
+		b.addEmail(chatID, recipient)
+
+	case "confirm":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите код из письма: /email confirm 123456")
+			return
+		}
+		b.confirmEmail(chatID, args[1])
+
+	case "remove":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите номер адреса: /email remove 1")
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		if err != nil || index < 1 || index > len(config.Emails) {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, "Адреса с таким номером нет. Список адресов: /email")
+			return
+		}
+		config.Emails = append(config.Emails[:index-1], config.Emails[index:]...)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, fmt.Sprintf("Адрес %d удалён.", index))
+
+	default:
+		b.SendMessage(chatID, emailUsage)
+	}
+}
+
+func (b *Bot) sendEmails(chatID int64) {
+	config, _ := b.GetConfig(chatID)
+
+	if len(config.Emails) == 0 {
+		b.SendMessage(chatID, "Сводки по почте не настроены.\n\n"+emailUsage)
+		return
+	}
+
+	var lines []string
+	for i, recipient := range config.Emails {
+		line := fmt.Sprintf("%d. %s", i+1, formatEmailRecipient(recipient))
+		if !recipient.Confirmed {
+			line += " (не подтверждён)"
+		}
+		lines = append(lines, line)
+	}
+	b.SendMessage(chatID, "Сводки по почте:\n"+strings.Join(lines, "\n")+
+		"\n\nУдалить адрес: /email remove <номер>")
+}
+
+// addEmail добавляет адрес или меняет расписание уже добавленного. На неподтверждённый адрес
+// уходит письмо с кодом; сводки на него не отправляются, пока код не введут через /email confirm.
+func (b *Bot) addEmail(chatID int64, recipient models.EmailRecipient) {
+	now := time.Now()
+
+	b.configMutex.Lock()
+	config := b.getOrCreateConfig(chatID)
+	index, unconfirmed := -1, 0
+	for i := range config.Emails {
+		if strings.EqualFold(config.Emails[i].Address, recipient.Address) {
+			index = i
+		} else if !config.Emails[i].Confirmed {
+			unconfirmed++
+		}
+	}
+
+	if index >= 0 {
+		existing := &config.Emails[index]
+		existing.Mode, existing.At = recipient.Mode, recipient.At
+		if existing.Confirmed {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, "Сводки будут приходить на "+formatEmailRecipient(*existing))
+			return
+		}
+		if wait := existing.CodeSent.Add(emailCodeResend).Sub(now); existing.Code != "" && wait > 0 {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, fmt.Sprintf("Код уже отправлен на <code>%s</code>. Повторно отправить письмо можно через %d мин.",
+				html.EscapeString(existing.Address), int(wait.Minutes())+1))
+			return
+		}
+	} else if unconfirmed >= maxUnconfirmedEmails {
+		b.configMutex.Unlock()
+		b.SendMessage(chatID, "Слишком много неподтверждённых адресов. Подтвердите или удалите их: /email")
+		return
+	} else {
+		config.Emails = append(config.Emails, recipient)
+		index = len(config.Emails) - 1
+	}
+
+	code, err := newEmailCode()
+	if err != nil {
+		b.configMutex.Unlock()
+		log.Printf("Failed to generate email confirmation code: %v", err)
+		b.SendMessage(chatID, "Не удалось отправить код подтверждения, попробуйте позже.")
+		return
+	}
+	pending := &config.Emails[index]
+	pending.Code, pending.CodeSent, pending.CodeAttempts = code, now, 0
+	address := pending.Address
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, "На <code>"+html.EscapeString(address)+"</code> отправлено письмо с кодом. "+
+		"Сводки начнут приходить после подтверждения: /email confirm <код>")
+
+	// Письмо отправляется в фоне, чтобы медленный SMTP не задерживал обработку других команд.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), emailCodeSendTimeout)
+		defer cancel()
+
+		if err := b.sendEmailCode(ctx, address, code); err != nil {
+			log.Printf("Failed to send email confirmation for chat %d: %v", chatID, err)
+			b.UpdateConfig(chatID, func(config *models.MonitoringConfig) {
+				for i := range config.Emails {
+					if config.Emails[i].Code == code {
+						config.Emails[i].Code, config.Emails[i].CodeSent = "", time.Time{}
+					}
+				}
+			})
+			b.SendMessage(chatID, "Не удалось отправить письмо на <code>"+html.EscapeString(address)+
+				"</code>. Проверьте адрес и повторите /email add.")
+		}
+	}()
+}
+
+// confirmEmail подтверждает адрес, код которого совпал. Неверный код засчитывается всем
+// ожидающим подтверждения адресам чата, чтобы код нельзя было подобрать перебором.
+func (b *Bot) confirmEmail(chatID int64, code string) {
+	now := time.Now()
+
+	b.configMutex.Lock()
+	config := b.getOrCreateConfig(chatID)
+	var confirmed *models.EmailRecipient
+	pending := 0
+	for i := range config.Emails {
+		recipient := &config.Emails[i]
+		if recipient.Confirmed || recipient.Code == "" {
+			continue
+		}
+		if now.Sub(recipient.CodeSent) > emailCodeTTL || recipient.CodeAttempts >= maxEmailCodeAttempts {
+			recipient.Code = ""
+			continue
+		}
+		if recipient.Code == code {
+			recipient.Confirmed, recipient.Code, recipient.CodeAttempts = true, "", 0
+			copied := *recipient
+			confirmed = &copied
+			break
+		}
+		pending++
+	}
+	if confirmed == nil {
+		for i := range config.Emails {
+			if !config.Emails[i].Confirmed && config.Emails[i].Code != "" {
+				config.Emails[i].CodeAttempts++
+			}
+		}
+	}
+	b.configMutex.Unlock()
+
+	switch {
+	case confirmed != nil:
+		b.SendMessage(chatID, "Адрес подтверждён. Сводки будут приходить на "+formatEmailRecipient(*confirmed))
+	case pending == 0:
+		b.SendMessage(chatID, "Нет адресов, ожидающих подтверждения. Запросите новый код: /email add <адрес>")
+	default:
+		b.SendMessage(chatID, "Неверный код. Проверьте письмо или запросите новый код: /email add <адрес>")
+	}
+}
+
+// newEmailCode возвращает случайный шестизначный код подтверждения.
+func newEmailCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func formatEmailRecipient(recipient models.EmailRecipient) string {
+	schedule := "раз в час"
+	if recipient.Mode == models.DigestDaily {
+		schedule = "раз в день в " + recipient.At
+	}
+	return "<code>" + html.EscapeString(recipient.Address) + "</code> - " + schedule
+}
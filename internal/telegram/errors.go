@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// isChatUnavailable сообщает, что в чат больше нельзя писать:
+// пользователь заблокировал бота, бота удалили из группы или чат не существует.
+func isChatUnavailable(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if apiErr.Code == 403 {
+		return true
+	}
+
+	return apiErr.Code == 400 && strings.Contains(strings.ToLower(apiErr.Message), "chat not found")
+}
+
+// migratedChatID возвращает ID супергруппы, если группа была преобразована.
+func migratedChatID(err error) int64 {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.MigrateToChatID
+	}
+	return 0
+}
+
+func isThreadNotFound(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == 400 && strings.Contains(strings.ToLower(apiErr.Message), "thread not found")
+}
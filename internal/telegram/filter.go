@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/filter"
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const filterUsage = "Правила фильтрации коммитов:\n" +
+	"/filter include|exclude author <логин> - по автору (поддерживается *)\n" +
+	"/filter include|exclude message <regexp> - по сообщению коммита\n" +
+	"/filter include|exclude branch <glob> - по ветке, например release/* (include включает опрос этих веток)\n" +
+	"/filter include|exclude path <glob> - по изменённым файлам, например src/** или **/*.md\n" +
+	"/filter remove <номер> - удалить правило\n" +
+	"/filter clear - удалить все правила\n\n" +
+	"Пример: /filter exclude author dependabot[bot]"
+
+func (b *Bot) handleFilter(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) == 0 {
+		b.sendFilters(chatID)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "clear":
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).Filters = nil
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Все правила фильтрации удалены.")
+
+	case "remove":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите номер правила: /filter remove 1")
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		if err != nil || index < 1 || index > len(config.Filters) {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, "Правила с таким номером нет. Список правил: /filter")
+			return
+		}
+		config.Filters = append(config.Filters[:index-1], config.Filters[index:]...)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, fmt.Sprintf("Правило %d удалено.", index))
+
+	case models.FilterInclude, models.FilterExclude:
+		if len(args) < 3 {
+			b.SendMessage(chatID, filterUsage)
+			return
+		}
+
+		rule := models.FilterRule{
+			Mode:    strings.ToLower(args[0]),
+			Field:   strings.ToLower(args[1]),
+			Pattern: strings.Join(args[2:], " "),
+		}
+		if err := filter.Validate(rule); err != nil {
+			b.SendMessage(chatID, "Некорректное правило: "+html.EscapeString(err.Error())+"\n\n"+filterUsage)
+			return
+		}
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		config.Filters = append(config.Filters, rule)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Правило добавлено: "+formatFilterRule(rule))
+
+	default:
+		b.SendMessage(chatID, filterUsage)
+	}
+}
+
+func (b *Bot) sendFilters(chatID int64) {
+	config, _ := b.GetConfig(chatID)
+
+	if len(config.Filters) == 0 {
+		b.SendMessage(chatID, "Правил фильтрации нет, приходят уведомления обо всех коммитах.\n\n"+filterUsage)
+		return
+	}
+
+	var lines []string
+	for i, rule := range config.Filters {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, formatFilterRule(rule)))
+	}
+	b.SendMessage(chatID, "Правила фильтрации коммитов:\n"+strings.Join(lines, "\n")+
+		"\n\nУдалить правило: /filter remove <номер>")
+}
+
+func formatFilterRule(rule models.FilterRule) string {
+	mode := "только"
+	if rule.Mode == models.FilterExclude {
+		mode = "кроме"
+	}
+
+	field := map[string]string{
+		models.FilterAuthor:  "автор",
+		models.FilterMessage: "сообщение",
+		models.FilterBranch:  "ветка",
+		models.FilterPath:    "путь",
+	}[rule.Field]
+
+	return fmt.Sprintf("%s %s <code>%s</code>", mode, field, html.EscapeString(rule.Pattern))
+}
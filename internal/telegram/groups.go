@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Команды, меняющие подписки чата или дорогие в исполнении. В группах их по умолчанию могут
+// выполнять только администраторы.
+var managementCommands = map[string]bool{
+	"track":    true,
+	"interval": true,
+	"stop":     true,
+	"pause":    true,
+	"resume":   true,
+	"check":    true,
+	"access":   true,
+	"route":    true,
+	"digest":   true,
+	"timezone": true,
+	"quiet":    true,
+	"snooze":   true,
+	"settings": true,
+	"filter":   true,
+	"alert":    true,
+	"secrets":  true,
+	"sink":     true,
+	"email":    true,
+	// Не меняют подписки, но собирают данные десятками запросов к GitHub API.
+	"report":    true,
+	"chart":     true,
+	"changelog": true,
+}
+
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// isAddressedToMe отбрасывает команды вида /track@OtherBot, адресованные другим ботам.
+func (b *Bot) isAddressedToMe(message *tgbotapi.Message) bool {
+	command := message.CommandWithAt()
+	i := strings.Index(command, "@")
+	if i == -1 {
+		return true
+	}
+	return strings.EqualFold(command[i+1:], b.api.Self.UserName)
+}
+
+func (b *Bot) canManage(message *tgbotapi.Message) bool {
+	return b.canManageChat(message.Chat, message.From, message.SenderChat, message.Command())
+}
+
+// canManageChat проверяет права на изменение подписок; используется и для команд, и для кнопок.
+func (b *Bot) canManageChat(chat *tgbotapi.Chat, from *tgbotapi.User, senderChat *tgbotapi.Chat, command string) bool {
+	if !isGroupChat(chat) {
+		return true
+	}
+
+	chatID := chat.ID
+
+	b.configMutex.RLock()
+	config, exists := b.monitoringConfigs[chatID]
+	allowMembers := exists && config.AllowMembers
+	b.configMutex.RUnlock()
+
+	// Настройку доступа всегда меняют только администраторы.
+	if allowMembers && command != "access" {
+		return true
+	}
+
+	// Анонимный администратор пишет от имени самой группы.
+	if senderChat != nil && senderChat.ID == chatID {
+		return true
+	}
+
+	if from == nil {
+		return false
+	}
+
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: chatID,
+			UserID: from.ID,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to get chat member %d in chat %d: %v", from.ID, chatID, err)
+		return false
+	}
+
+	return member.IsAdministrator() || member.IsCreator()
+}
+
+func (b *Bot) handleAccess(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+
+	if !isGroupChat(update.Message.Chat) {
+		b.SendMessage(chatID, "Настройка доступа доступна только в группах.")
+		return
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) < 1 {
+		b.configMutex.RLock()
+		config, exists := b.monitoringConfigs[chatID]
+		allowMembers := exists && config.AllowMembers
+		b.configMutex.RUnlock()
+
+		current := "только администраторы"
+		if allowMembers {
+			current = "все участники"
+		}
+		b.SendMessage(chatID, "Управлять подписками могут: <b>"+current+"</b>\n"+
+			"Изменить: /access admins или /access all")
+		return
+	}
+
+	var allowMembers bool
+	switch strings.ToLower(args[0]) {
+	case "admins":
+		allowMembers = false
+	case "all":
+		allowMembers = true
+	default:
+		b.SendMessage(chatID, "Используйте /access admins или /access all")
+		return
+	}
+
+	b.configMutex.Lock()
+	b.getOrCreateConfig(chatID).AllowMembers = allowMembers
+	b.configMutex.Unlock()
+
+	if allowMembers {
+		b.SendMessage(chatID, "Теперь управлять подписками могут все участники группы.")
+	} else {
+		b.SendMessage(chatID, "Теперь управлять подписками могут только администраторы группы.")
+	}
+}
@@ -0,0 +1,61 @@
+package telegram
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (b *Bot) handlePause(update tgbotapi.Update) {
+	b.setPaused(update.Message.Chat.ID, true)
+}
+
+func (b *Bot) handleResume(update tgbotapi.Update) {
+	b.setPaused(update.Message.Chat.ID, false)
+}
+
+func (b *Bot) setPaused(chatID int64, paused bool) {
+	b.configMutex.Lock()
+	config, exists := b.monitoringConfigs[chatID]
+	if !exists || !config.IsActive {
+		b.configMutex.Unlock()
+		b.SendMessage(chatID, "Мониторинг не активен. Используйте /track <username> для начала отслеживания.")
+		return
+	}
+	if config.Paused == paused {
+		b.configMutex.Unlock()
+		if paused {
+			b.SendMessage(chatID, "Мониторинг уже приостановлен. Возобновить: /resume")
+		} else {
+			b.SendMessage(chatID, "Мониторинг не приостановлен.")
+		}
+		return
+	}
+	config.Paused = paused
+	username := config.GitHubUsername
+	b.configMutex.Unlock()
+
+	if paused {
+		b.callbackChan <- MonitoringCallback{Type: "pause", ChatID: chatID, Username: username}
+		b.SendMessage(chatID, fmt.Sprintf("Мониторинг аккаунта <b>%s</b> приостановлен. Возобновить: /resume", username))
+		return
+	}
+
+	b.callbackChan <- MonitoringCallback{Type: "resume", ChatID: chatID, Username: username}
+	b.SendMessage(chatID, fmt.Sprintf("Мониторинг аккаунта <b>%s</b> возобновлён, проверяю изменения.", username))
+}
+
+// handleCheck запускает внеочередную проверку. Планировщик не начнёт её, пока идёт
+// плановый опрос, а следующий плановый опрос отсчитывается от её завершения.
+func (b *Bot) handleCheck(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+
+	config, exists := b.GetConfig(chatID)
+	if !exists || !config.IsActive {
+		b.SendMessage(chatID, "Мониторинг не активен. Используйте /track <username> для начала отслеживания.")
+		return
+	}
+
+	b.callbackChan <- MonitoringCallback{Type: "check", ChatID: chatID, Username: config.GitHubUsername}
+	b.SendMessage(chatID, fmt.Sprintf("🔍 Проверяю аккаунт <b>%s</b>...", config.GitHubUsername))
+}
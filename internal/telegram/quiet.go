@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (b *Bot) handleTimezone(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) < 1 {
+		config, _ := b.GetConfig(chatID)
+		b.SendMessage(chatID, fmt.Sprintf("Часовой пояс чата: <b>%s</b>\nИзменить: /timezone Europe/Moscow",
+			config.Location().String()))
+		return
+	}
+
+	loc, err := time.LoadLocation(args[0])
+	if err != nil {
+		b.SendMessage(chatID, "Неизвестный часовой пояс. Используйте имя из базы IANA, например Europe/Moscow или Asia/Yekaterinburg.")
+		return
+	}
+
+	b.configMutex.Lock()
+	b.getOrCreateConfig(chatID).TimeZone = loc.String()
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, fmt.Sprintf("Часовой пояс установлен: <b>%s</b>\nТекущее время: %s",
+		loc.String(), time.Now().In(loc).Format("15:04")))
+}
+
+func (b *Bot) handleQuiet(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(strings.ToLower(update.Message.CommandArguments()))
+
+	if len(args) < 1 {
+		config, _ := b.GetConfig(chatID)
+		status := "выключены"
+		if config.Quiet.Start != "" {
+			status = fmt.Sprintf("%s-%s (%s), часовой пояс %s", config.Quiet.Start, config.Quiet.End,
+				quietModeTitle(config.Quiet.Mode), config.Location().String())
+		}
+		b.SendMessage(chatID, "Тихие часы: <b>"+status+"</b>\n"+
+			"Изменить: /quiet 23:00-08:00 [hold|silent] или /quiet off\n"+
+			"hold - придержать уведомления до утра, silent - присылать без звука")
+		return
+	}
+
+	if args[0] == "off" {
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).Quiet = models.QuietSettings{}
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Тихие часы выключены.")
+		return
+	}
+
+	bounds := strings.Split(args[0], "-")
+	if len(bounds) != 2 {
+		b.SendMessage(chatID, "Укажите интервал в формате ЧЧ:ММ-ЧЧ:ММ, например: /quiet 23:00-08:00")
+		return
+	}
+	for _, bound := range bounds {
+		if _, err := time.Parse("15:04", bound); err != nil {
+			b.SendMessage(chatID, "Укажите интервал в формате ЧЧ:ММ-ЧЧ:ММ, например: /quiet 23:00-08:00")
+			return
+		}
+	}
+
+	mode := models.QuietHold
+	if len(args) > 1 {
+		if args[1] != models.QuietHold && args[1] != models.QuietSilent {
+			b.SendMessage(chatID, "Режим тихих часов: hold или silent")
+			return
+		}
+		mode = args[1]
+	}
+
+	b.configMutex.Lock()
+	config := b.getOrCreateConfig(chatID)
+	config.Quiet = models.QuietSettings{Start: bounds[0], End: bounds[1], Mode: mode}
+	location := config.Location().String()
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, fmt.Sprintf("Тихие часы: %s-%s (%s), часовой пояс %s",
+		bounds[0], bounds[1], quietModeTitle(mode), location))
+}
+
+func (b *Bot) handleSnooze(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(strings.ToLower(update.Message.CommandArguments()))
+
+	if len(args) < 1 {
+		b.SendMessage(chatID, "Укажите длительность, например: /snooze 2h или /snooze 30m\nОтменить: /snooze off")
+		return
+	}
+
+	if args[0] == "off" {
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).SnoozeUntil = time.Time{}
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Уведомления снова включены.")
+		return
+	}
+
+	duration, err := time.ParseDuration(args[0])
+	if err != nil || duration <= 0 {
+		b.SendMessage(chatID, "Не удалось разобрать длительность. Примеры: 30m, 2h, 1h30m")
+		return
+	}
+
+	until := time.Now().Add(duration)
+
+	b.configMutex.Lock()
+	config := b.getOrCreateConfig(chatID)
+	config.SnoozeUntil = until
+	formatted := config.FormatTime(until)
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, fmt.Sprintf("Уведомления отложены до %s. Накопившиеся уведомления придут после этого.", formatted))
+}
+
+func quietModeTitle(mode string) string {
+	if mode == models.QuietSilent {
+		return "без звука"
+	}
+	return "придерживать до утра"
+}
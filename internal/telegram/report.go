@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/report"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const reportUsage = "/report [аккаунт] - отчёт за последние 7 дней\n" +
+	"/report schedule <mon|tue|wed|thu|fri|sat|sun> [ЧЧ:ММ] - когда присылать еженедельный отчёт\n" +
+	"/report schedule off - не присылать отчёт автоматически"
+
+func (b *Bot) handleReport(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) > 0 && strings.EqualFold(args[0], "schedule") {
+		b.handleReportSchedule(update, args[1:])
+		return
+	}
+
+	account := ""
+	if len(args) > 0 {
+		account = args[0]
+	} else {
+		config, _ := b.GetConfig(chatID)
+		account = config.GitHubUsername
+	}
+	if account == "" {
+		b.SendMessage(chatID, "Сначала укажите аккаунт для отслеживания с помощью команды /track <username>\n\n"+reportUsage)
+		return
+	}
+
+	b.callbackChan <- MonitoringCallback{
+		Type:     "report",
+		ChatID:   chatID,
+		Username: account,
+	}
+
+	b.SendMessage(chatID, "Собираю отчёт по аккаунту <b>"+html.EscapeString(account)+"</b>, это может занять минуту...")
+}
+
+// handleReportSchedule меняет настройки, поэтому в группах проверяет права отдельно:
+// сам /report доступен всем участникам.
+func (b *Bot) handleReportSchedule(update tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+
+	if len(args) == 0 {
+		config, _ := b.GetConfig(chatID)
+		status := "выключен"
+		if !config.Report.Off {
+			day, at := config.Report.Day, config.Report.At
+			if day == "" {
+				day = report.DefaultDay
+			}
+			if at == "" {
+				at = report.DefaultAt
+			}
+			status = fmt.Sprintf("%s в %s (%s)", day, at, config.Location().String())
+		}
+		b.SendMessage(chatID, "Еженедельный отчёт: <b>"+status+"</b>\n\n"+reportUsage)
+		return
+	}
+
+	if !b.canManage(update.Message) {
+		b.SendMessage(chatID, "Менять расписание отчёта в этой группе могут только администраторы.")
+		return
+	}
+
+	day := strings.ToLower(args[0])
+	if day == "off" {
+		b.UpdateConfig(chatID, func(config *models.MonitoringConfig) {
+			config.Report.Off = true
+		})
+		b.SendMessage(chatID, "Еженедельный отчёт выключен. Получить отчёт вручную: /report")
+		return
+	}
+
+	if _, ok := report.Weekdays[day]; !ok {
+		b.SendMessage(chatID, reportUsage)
+		return
+	}
+
+	at := report.DefaultAt
+	if len(args) > 1 {
+		if _, err := time.Parse("15:04", args[1]); err != nil {
+			b.SendMessage(chatID, "Укажите время в формате ЧЧ:ММ, например: /report schedule fri 18:00")
+			return
+		}
+		at = args[1]
+	}
+
+	b.UpdateConfig(chatID, func(config *models.MonitoringConfig) {
+		config.Report.Off = false
+		config.Report.Day = day
+		config.Report.At = at
+	})
+	b.SendMessage(chatID, fmt.Sprintf("Еженедельный отчёт будет приходить: %s в %s.", day, at))
+}
@@ -0,0 +1,144 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ThreadFor возвращает тему форума для уведомлений о репозитории.
+// Маршрут репозитория (ключ owner/repo) важнее маршрута аккаунта; 0 означает общий чат.
+func (b *Bot) ThreadFor(chatID int64, account, repo string) int {
+	b.configMutex.RLock()
+	defer b.configMutex.RUnlock()
+
+	config, exists := b.monitoringConfigs[chatID]
+	if !exists {
+		return 0
+	}
+
+	account = strings.ToLower(account)
+	if repo != "" {
+		if threadID, ok := config.Routes[account+"/"+strings.ToLower(repo)]; ok {
+			return threadID
+		}
+	}
+	return config.Routes[account]
+}
+
+// routeTarget - цель /route: аккаунт, репозиторий или owner/repo из допустимых в GitHub символов.
+var routeTarget = regexp.MustCompile(`^/?[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)?/?$`)
+
+// routeKey приводит цель /route к ключу маршрута: имя аккаунта или owner/repo.
+// Репозиторий без владельца относится к отслеживаемому аккаунту.
+func routeKey(target, account string) (string, bool) {
+	target = strings.Trim(strings.ToLower(target), "/")
+	if strings.Contains(target, "/") || strings.EqualFold(target, account) {
+		return target, true
+	}
+	if account == "" {
+		return "", false
+	}
+	return strings.ToLower(account) + "/" + target, true
+}
+
+// migrateRoutes переводит маршруты, сохранённые по имени репозитория без владельца,
+// на ключи owner/repo: иначе одноимённые репозитории разных аккаунтов делили бы тему.
+func migrateRoutes(config *models.MonitoringConfig) {
+	for target, threadID := range config.Routes {
+		key, ok := routeKey(target, config.GitHubUsername)
+		if !ok || key == target {
+			continue
+		}
+		delete(config.Routes, target)
+		config.Routes[key] = threadID
+	}
+}
+
+func (b *Bot) handleRoute(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) == 0 {
+		b.sendRoutes(chatID)
+		return
+	}
+
+	if len(args) < 2 {
+		b.SendMessage(chatID, "Пример: /route backend-api 42 или /route org/backend-api 42\n"+
+			"ID темы - число после ID чата в ссылке на сообщение темы: t.me/c/123/<b>42</b>/100\n"+
+			"Удалить маршрут: /route backend-api off")
+		return
+	}
+
+	b.configMutex.RLock()
+	var account string
+	if config, exists := b.monitoringConfigs[chatID]; exists {
+		account = config.GitHubUsername
+	}
+	b.configMutex.RUnlock()
+
+	if !routeTarget.MatchString(args[0]) {
+		b.SendMessage(chatID, "Укажите аккаунт, репозиторий или owner/repo: латинские буквы, цифры, '-', '_' и '.'.")
+		return
+	}
+
+	target, ok := routeKey(args[0], account)
+	if !ok {
+		b.SendMessage(chatID, "Укажите репозиторий вместе с владельцем: /route owner/repo 42")
+		return
+	}
+
+	if strings.EqualFold(args[1], "off") {
+		b.configMutex.Lock()
+		if config, exists := b.monitoringConfigs[chatID]; exists {
+			delete(config.Routes, target)
+		}
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, fmt.Sprintf("Маршрут для <b>%s</b> удалён.", html.EscapeString(target)))
+		return
+	}
+
+	threadID, err := strconv.Atoi(args[1])
+	if err != nil || threadID < 1 {
+		b.SendMessage(chatID, "ID темы должен быть положительным числом.")
+		return
+	}
+
+	b.configMutex.Lock()
+	config := b.getOrCreateConfig(chatID)
+	if config.Routes == nil {
+		config.Routes = make(map[string]int)
+	}
+	config.Routes[target] = threadID
+	b.configMutex.Unlock()
+
+	b.SendMessage(chatID, fmt.Sprintf("Уведомления для <b>%s</b> будут отправляться в тему %d.", html.EscapeString(target), threadID))
+}
+
+func (b *Bot) sendRoutes(chatID int64) {
+	b.configMutex.RLock()
+	var lines []string
+	if config, exists := b.monitoringConfigs[chatID]; exists {
+		for target, threadID := range config.Routes {
+			lines = append(lines, fmt.Sprintf("• %s → тема %d", html.EscapeString(target), threadID))
+		}
+	}
+	b.configMutex.RUnlock()
+
+	if len(lines) == 0 {
+		b.SendMessage(chatID, "Маршруты не настроены, все уведомления отправляются в общий чат.\n"+
+			"Добавить: /route &lt;аккаунт|репозиторий|owner/repo&gt; &lt;ID темы&gt;")
+		return
+	}
+
+	sort.Strings(lines)
+	b.SendMessage(chatID, "Маршруты уведомлений:\n"+strings.Join(lines, "\n"))
+}
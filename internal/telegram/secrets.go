@@ -0,0 +1,123 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	"github.com/DragonAirDragon/GO/internal/secrets"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const secretsUsage = "Проверка новых коммитов на утечку ключей и токенов:\n" +
+	"/secrets on|off - включить или выключить проверку\n" +
+	"/secrets ignore <репозиторий|*> [правило|*] [путь] - не сообщать о находках, например: /secrets ignore api high-entropy testdata/**\n" +
+	"/secrets unignore <номер> - удалить исключение"
+
+func (b *Bot) handleSecrets(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) == 0 {
+		b.sendSecretsSettings(chatID)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on", "off":
+		off := strings.ToLower(args[0]) == "off"
+
+		b.configMutex.Lock()
+		b.getOrCreateConfig(chatID).SecretScanOff = off
+		b.configMutex.Unlock()
+
+		if off {
+			b.SendMessage(chatID, "Проверка коммитов на секреты выключена.")
+		} else {
+			b.SendMessage(chatID, "Проверка коммитов на секреты включена.")
+		}
+
+	case "ignore":
+		if len(args) < 2 {
+			b.SendMessage(chatID, secretsUsage)
+			return
+		}
+
+		ignore := models.SecretIgnore{Repo: args[1]}
+		if len(args) > 2 && args[2] != "*" {
+			if !secrets.HasRule(args[2]) {
+				b.SendMessage(chatID, "Неизвестное правило. Доступные правила: "+strings.Join(secrets.RuleIDs(), ", "))
+				return
+			}
+			ignore.Rule = args[2]
+		}
+		if len(args) > 3 {
+			ignore.Path = args[3]
+		}
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		config.SecretIgnores = append(config.SecretIgnores, ignore)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, "Исключение добавлено: "+formatSecretIgnore(ignore))
+
+	case "unignore":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите номер исключения: /secrets unignore 1")
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		if err != nil || index < 1 || index > len(config.SecretIgnores) {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, "Исключения с таким номером нет. Список исключений: /secrets")
+			return
+		}
+		config.SecretIgnores = append(config.SecretIgnores[:index-1], config.SecretIgnores[index:]...)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, fmt.Sprintf("Исключение %d удалено.", index))
+
+	default:
+		b.SendMessage(chatID, secretsUsage)
+	}
+}
+
+func (b *Bot) sendSecretsSettings(chatID int64) {
+	config, _ := b.GetConfig(chatID)
+
+	status := "включена"
+	if config.SecretScanOff {
+		status = "выключена"
+	}
+
+	text := "Проверка на секреты: <b>" + status + "</b>\n" +
+		"Правила: " + strings.Join(secrets.RuleIDs(), ", ") + "\n"
+
+	if len(config.SecretIgnores) > 0 {
+		text += "\nИсключения:\n"
+		for i, ignore := range config.SecretIgnores {
+			text += fmt.Sprintf("%d. %s\n", i+1, formatSecretIgnore(ignore))
+		}
+	}
+
+	b.SendMessage(chatID, text+"\n"+secretsUsage)
+}
+
+func formatSecretIgnore(ignore models.SecretIgnore) string {
+	rule := ignore.Rule
+	if rule == "" {
+		rule = "все правила"
+	}
+
+	text := "репозиторий <code>" + html.EscapeString(ignore.Repo) + "</code>, " + rule
+	if ignore.Path != "" {
+		text += ", путь <code>" + html.EscapeString(ignore.Path) + "</code>"
+	}
+	return text
+}
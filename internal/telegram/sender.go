@@ -0,0 +1,396 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	globalSendInterval  = time.Second / 30 // Telegram: не более 30 сообщений в секунду
+	perChatSendInterval = time.Second      // и не более 1 сообщения в секунду в один чат
+	maxSendAttempts     = 5
+	retryBaseDelay      = time.Second
+	queueCapacity       = 1000
+)
+
+var ErrSenderStopped = errors.New("telegram sender is stopped")
+
+// DeliveryFailure - сообщение, которое не удалось доставить после всех попыток.
+// Такое сообщение уже передано обработчику ошибок, повторять его отправку не нужно.
+type DeliveryFailure struct {
+	Message  OutgoingMessage
+	Attempts int
+	Err      error
+}
+
+func (f DeliveryFailure) Error() string {
+	return fmt.Sprintf("message to chat %d failed after %d attempts: %v", f.Message.ChatID, f.Attempts, f.Err)
+}
+
+func (f DeliveryFailure) Unwrap() error {
+	return f.Err
+}
+
+type OutgoingMessage struct {
+	ChatID   int64
+	ThreadID int // message_thread_id темы форума, 0 - общий чат
+	Text     string
+	Silent   bool // disable_notification: доставить без звука
+
+	ReplyMarkup interface{}
+	Photo       []byte // PNG; если задан, отправляется sendPhoto, а Text становится подписью
+}
+
+type queuedMessage struct {
+	OutgoingMessage
+	attempts  int
+	notBefore time.Time
+	result    chan error // если не nil, получает итог отправки
+}
+
+// finish сообщает итог отправки тому, кто его ждёт.
+func (msg *queuedMessage) finish(err error) {
+	if msg.result != nil {
+		msg.result <- err
+	}
+}
+
+type sender struct {
+	api *tgbotapi.BotAPI
+
+	mu       sync.Mutex
+	queues   map[int64][]*queuedMessage
+	order    []int64
+	inFlight map[int64]bool
+	nextSend map[int64]time.Time
+	migrated map[int64]int64
+	pending  int
+	stopped  bool
+
+	onUnavailable func(chatID int64, err error)
+	onMigrated    func(oldChatID, newChatID int64)
+	// onFailure получает каждое сообщение, которое не удалось доставить, в том числе не принятое
+	// в очередь и оставшееся в ней при остановке. Вызывается синхронно, поэтому отчёт не теряется.
+	onFailure func(failure DeliveryFailure)
+
+	wake chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newSender(api *tgbotapi.BotAPI) *sender {
+	return &sender{
+		api:      api,
+		queues:   make(map[int64][]*queuedMessage),
+		inFlight: make(map[int64]bool),
+		nextSend: make(map[int64]time.Time),
+		migrated: make(map[int64]int64),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// enqueue ставит сообщение в очередь без ожидания результата. Сообщение, которое очередь
+// не приняла, сохраняется как недоставленное.
+func (s *sender) enqueue(msg OutgoingMessage) error {
+	queued := &queuedMessage{OutgoingMessage: msg}
+	if err := s.enqueueQueued(queued); err != nil {
+		s.reportFailure(queued, err)
+		return err
+	}
+	return nil
+}
+
+func (s *sender) enqueueQueued(msg *queuedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrSenderStopped
+	}
+	if s.pending >= queueCapacity {
+		return fmt.Errorf("send queue is full (%d messages)", s.pending)
+	}
+
+	if newChatID, ok := s.migrated[msg.ChatID]; ok {
+		msg.ChatID = newChatID
+	}
+
+	s.push(msg, false)
+	s.signal()
+	return nil
+}
+
+// redirect перенаправляет очередь и все будущие сообщения старого чата в новый.
+// Должен вызываться под s.mu.
+func (s *sender) redirect(oldChatID, newChatID int64) {
+	s.migrated[oldChatID] = newChatID
+
+	queue := s.queues[oldChatID]
+	if len(queue) == 0 {
+		return
+	}
+
+	delete(s.queues, oldChatID)
+	for i, chatID := range s.order {
+		if chatID == oldChatID {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+
+	s.pending -= len(queue)
+	for _, msg := range queue {
+		msg.ChatID = newChatID
+		s.push(msg, false)
+	}
+}
+
+// push должен вызываться под s.mu.
+func (s *sender) push(msg *queuedMessage, front bool) {
+	queue, exists := s.queues[msg.ChatID]
+	if !exists || len(queue) == 0 {
+		s.order = append(s.order, msg.ChatID)
+	}
+	if front {
+		s.queues[msg.ChatID] = append([]*queuedMessage{msg}, queue...)
+	} else {
+		s.queues[msg.ChatID] = append(queue, msg)
+	}
+	s.pending++
+}
+
+func (s *sender) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *sender) run() {
+	var lastSend time.Time
+
+	for {
+		msg, wait := s.next(time.Now(), lastSend)
+		if msg != nil {
+			lastSend = time.Now()
+			go s.deliver(msg)
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.done:
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// next выбирает следующее сообщение с учётом глобального и per-chat лимитов.
+// Если отправлять пока нечего, возвращает время ожидания.
+func (s *sender) next(now, lastSend time.Time) (*queuedMessage, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := time.Minute
+	if s.stopped {
+		return nil, wait
+	}
+	if globalReady := lastSend.Add(globalSendInterval); now.Before(globalReady) {
+		return nil, globalReady.Sub(now)
+	}
+
+	for i, chatID := range s.order {
+		if s.inFlight[chatID] {
+			continue
+		}
+
+		msg := s.queues[chatID][0]
+		ready := s.nextSend[chatID]
+		if msg.notBefore.After(ready) {
+			ready = msg.notBefore
+		}
+		if now.Before(ready) {
+			if d := ready.Sub(now); d < wait {
+				wait = d
+			}
+			continue
+		}
+
+		s.queues[chatID] = s.queues[chatID][1:]
+		s.pending--
+		s.order = append(s.order[:i], s.order[i+1:]...)
+		if len(s.queues[chatID]) > 0 {
+			s.order = append(s.order, chatID)
+		} else {
+			delete(s.queues, chatID)
+		}
+		s.inFlight[chatID] = true
+		s.wg.Add(1)
+		return msg, 0
+	}
+
+	return nil, wait
+}
+
+func (s *sender) deliver(msg *queuedMessage) {
+	defer s.wg.Done()
+
+	msg.attempts++
+	err := s.send(msg)
+
+	s.mu.Lock()
+	delete(s.inFlight, msg.ChatID)
+	s.nextSend[msg.ChatID] = time.Now().Add(perChatSendInterval)
+
+	if newChatID := migratedChatID(err); newChatID != 0 && !s.stopped {
+		oldChatID := msg.ChatID
+		log.Printf("Chat %d was migrated to %d, redirecting messages", oldChatID, newChatID)
+		s.redirect(oldChatID, newChatID)
+		msg.ChatID = newChatID
+		s.push(msg, true)
+		s.mu.Unlock()
+
+		if s.onMigrated != nil {
+			s.onMigrated(oldChatID, newChatID)
+		}
+		s.signal()
+		return
+	}
+
+	if msg.ThreadID != 0 && isThreadNotFound(err) && !s.stopped {
+		log.Printf("Topic %d in chat %d not found, sending to the general chat", msg.ThreadID, msg.ChatID)
+		msg.ThreadID = 0
+		s.push(msg, true)
+		s.mu.Unlock()
+		s.signal()
+		return
+	}
+
+	if err != nil {
+		if delay, retry := retryDelay(err, msg.attempts); retry && !s.stopped {
+			log.Printf("Failed to send message to chat %d (attempt %d), retrying in %s: %v",
+				msg.ChatID, msg.attempts, delay, err)
+			msg.notBefore = time.Now().Add(delay)
+			s.push(msg, true)
+		} else {
+			s.mu.Unlock()
+			failure := s.reportFailure(msg, err)
+			msg.finish(failure)
+			if isChatUnavailable(err) && s.onUnavailable != nil {
+				s.onUnavailable(msg.ChatID, err)
+			}
+			s.signal()
+			return
+		}
+	} else {
+		msg.finish(nil)
+	}
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// send использует MakeRequest напрямую: MessageConfig в этой версии библиотеки
+// не поддерживает message_thread_id.
+func (s *sender) send(msg *queuedMessage) error {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", msg.ChatID)
+	params.AddNonZero("message_thread_id", msg.ThreadID)
+	params["parse_mode"] = tgbotapi.ModeHTML
+	params.AddBool("disable_notification", msg.Silent)
+	if err := params.AddInterface("reply_markup", msg.ReplyMarkup); err != nil {
+		return err
+	}
+
+	if msg.Photo != nil {
+		params.AddNonEmpty("caption", msg.Text)
+		files := []tgbotapi.RequestFile{{
+			Name: "photo",
+			Data: tgbotapi.FileBytes{Name: "chart.png", Bytes: msg.Photo},
+		}}
+		_, err := s.api.UploadFiles("sendPhoto", params, files)
+		return err
+	}
+
+	params["text"] = msg.Text
+	_, err := s.api.MakeRequest("sendMessage", params)
+	return err
+}
+
+func (s *sender) reportFailure(msg *queuedMessage, err error) DeliveryFailure {
+	failure := DeliveryFailure{
+		Message:  msg.OutgoingMessage,
+		Attempts: msg.attempts,
+		Err:      err,
+	}
+
+	if s.onFailure != nil {
+		s.onFailure(failure)
+	}
+	return failure
+}
+
+// retryDelay решает, стоит ли повторять отправку и через сколько.
+// 429 повторяется через retry_after, 5xx и сетевые ошибки - с экспоненциальной задержкой,
+// остальные ошибки API считаются окончательными.
+func retryDelay(err error, attempts int) (time.Duration, bool) {
+	if attempts >= maxSendAttempts {
+		return 0, false
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == 429:
+			if apiErr.RetryAfter > 0 {
+				return time.Duration(apiErr.RetryAfter) * time.Second, true
+			}
+		case apiErr.Code >= 500:
+		default:
+			return 0, false
+		}
+	}
+
+	return retryBaseDelay << (attempts - 1), true
+}
+
+func (s *sender) stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	// Тот, кто ждёт результата, повторит отправку сам; остальные сообщения сохраняются как недоставленные.
+	var dropped []*queuedMessage
+	for _, queue := range s.queues {
+		for _, msg := range queue {
+			if msg.result != nil {
+				msg.finish(ErrSenderStopped)
+			} else {
+				dropped = append(dropped, msg)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+
+	if len(dropped) > 0 {
+		log.Printf("Telegram sender stopped with %d undelivered messages", len(dropped))
+	}
+	for _, msg := range dropped {
+		s.reportFailure(msg, ErrSenderStopped)
+	}
+}
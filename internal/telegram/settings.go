@@ -0,0 +1,119 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const settingsCallbackPrefix = "settings:"
+
+var eventTypeTitles = map[models.EventType]string{
+	models.EventNewRepo:     "Новые репозитории",
+	models.EventCommit:      "Коммиты",
+	models.EventRelease:     "Релизы",
+	models.EventPullRequest: "Pull requests",
+	models.EventCI:          "CI",
+	models.EventDependency:  "Зависимости",
+}
+
+func (b *Bot) handleSettings(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+
+	// Просмотр настроек ничего не меняет, поэтому запись для чата не создаётся.
+	config, _ := b.GetConfig(chatID)
+
+	b.Send(OutgoingMessage{
+		ChatID:      chatID,
+		Text:        settingsText(config),
+		ReplyMarkup: settingsKeyboard(config),
+	})
+}
+
+func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		return
+	}
+
+	if strings.HasPrefix(query.Data, settingsCallbackPrefix) {
+		b.handleSettingsCallback(query)
+		return
+	}
+
+	b.answerCallback(query.ID, "")
+}
+
+func (b *Bot) handleSettingsCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	eventType := models.EventType(strings.TrimPrefix(query.Data, settingsCallbackPrefix))
+
+	title, known := eventTypeTitles[eventType]
+	if !known {
+		b.answerCallback(query.ID, "Неизвестная настройка")
+		return
+	}
+
+	if !b.canManageChat(query.Message.Chat, query.From, nil, "settings") {
+		b.answerCallback(query.ID, "Менять настройки могут только администраторы.")
+		return
+	}
+
+	b.configMutex.Lock()
+	config := b.getOrCreateConfig(chatID)
+	if config.DisabledEvents == nil {
+		config.DisabledEvents = make(map[models.EventType]bool)
+	}
+	if config.DisabledEvents[eventType] {
+		delete(config.DisabledEvents, eventType)
+	} else {
+		config.DisabledEvents[eventType] = true
+	}
+	updated := config.Clone()
+	b.configMutex.Unlock()
+
+	b.saveConfig(chatID)
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID, settingsText(updated), settingsKeyboard(updated))
+	edit.ParseMode = tgbotapi.ModeHTML
+	if _, err := b.api.Request(edit); err != nil {
+		log.Printf("Failed to update settings message in chat %d: %v", chatID, err)
+	}
+
+	state := "включены"
+	if !updated.Wants(eventType) {
+		state = "выключены"
+	}
+	b.answerCallback(query.ID, fmt.Sprintf("%s: %s", title, state))
+}
+
+func (b *Bot) answerCallback(queryID, text string) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(queryID, text)); err != nil {
+		log.Printf("Failed to answer callback query: %v", err)
+	}
+}
+
+func settingsText(config models.MonitoringConfig) string {
+	account := "аккаунт не выбран"
+	if config.GitHubUsername != "" {
+		account = "<b>" + html.EscapeString(config.GitHubUsername) + "</b>"
+	}
+	return "⚙️ Уведомления для " + account + "\n" +
+		"Нажмите на тип события, чтобы включить или выключить его."
+}
+
+func settingsKeyboard(config models.MonitoringConfig) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, eventType := range models.EventTypes {
+		mark := "✅"
+		if !config.Wants(eventType) {
+			mark = "❌"
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(mark+" "+eventTypeTitles[eventType], settingsCallbackPrefix+string(eventType))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/DragonAirDragon/GO/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const sinkUsage = "Дополнительные получатели уведомлений подписки:\n" +
+	"/sink add slack <URL> - входящий webhook Slack\n" +
+	"/sink add discord <URL> - webhook канала Discord\n" +
+	"/sink add webhook <URL> - JSON POST на произвольный адрес\n" +
+	"/sink remove <номер> - удалить получателя\n\n" +
+	"Уведомления в этот чат продолжают приходить как обычно."
+
+func (b *Bot) handleSink(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+
+	if len(args) == 0 {
+		b.sendSinks(chatID)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(args) < 3 {
+			b.SendMessage(chatID, sinkUsage)
+			return
+		}
+
+		sink := models.Sink{Kind: strings.ToLower(args[1]), URL: args[2]}
+		if err := sink.Validate(); err != nil {
+			b.SendMessage(chatID, "Некорректный получатель: "+html.EscapeString(err.Error())+"\n\n"+sinkUsage)
+			return
+		}
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		config.Sinks = append(config.Sinks, sink)
+		b.configMutex.Unlock()
+
+		// Адрес webhook - это секрет, убираем его из истории чата, если хватает прав.
+		if _, err := b.api.Request(tgbotapi.NewDeleteMessage(chatID, update.Message.MessageID)); err != nil {
+			b.SendMessage(chatID, "Получатель добавлен: "+formatSink(sink)+"\n\nУдалите сообщение с адресом: он даёт право писать в ваш канал.")
+			return
+		}
+		b.SendMessage(chatID, "Получатель добавлен: "+formatSink(sink))
+
+	case "remove":
+		if len(args) < 2 {
+			b.SendMessage(chatID, "Укажите номер получателя: /sink remove 1")
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+
+		b.configMutex.Lock()
+		config := b.getOrCreateConfig(chatID)
+		if err != nil || index < 1 || index > len(config.Sinks) {
+			b.configMutex.Unlock()
+			b.SendMessage(chatID, "Получателя с таким номером нет. Список получателей: /sink")
+			return
+		}
+		config.Sinks = append(config.Sinks[:index-1], config.Sinks[index:]...)
+		b.configMutex.Unlock()
+
+		b.SendMessage(chatID, fmt.Sprintf("Получатель %d удалён.", index))
+
+	default:
+		b.SendMessage(chatID, sinkUsage)
+	}
+}
+
+func (b *Bot) sendSinks(chatID int64) {
+	config, _ := b.GetConfig(chatID)
+
+	if len(config.Sinks) == 0 {
+		b.SendMessage(chatID, "Уведомления приходят только в этот чат.\n\n"+sinkUsage)
+		return
+	}
+
+	var lines []string
+	for i, sink := range config.Sinks {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, formatSink(sink)))
+	}
+	b.SendMessage(chatID, "Уведомления также отправляются:\n"+strings.Join(lines, "\n")+
+		"\n\nУдалить получателя: /sink remove <номер>")
+}
+
+func formatSink(sink models.Sink) string {
+	name := map[string]string{
+		models.SinkSlack:   "Slack",
+		models.SinkDiscord: "Discord",
+		models.SinkWebhook: "Webhook",
+	}[sink.Kind]
+
+	return name + " <code>" + html.EscapeString(sink.MaskedURL()) + "</code>"
+}
@@ -29,3 +29,7 @@ func (db *PostgresDB) Close() {
 		db.pool.Close()
 	}
 }
+
+func (db *PostgresDB) Pool() *pgxpool.Pool {
+	return db.pool
+}